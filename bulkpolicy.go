@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"rcloneb/queue"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// updateBulkPolicy handles the menu setting one conflict policy on many
+// queue items: all of them, the selected ones, or those matching a glob
+func (m Model) updateBulkPolicy(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.policyGlobMode {
+		switch msg.String() {
+		case "esc":
+			m.policyGlobMode = false
+			m.policyGlob.Blur()
+			return m, nil
+		case "enter":
+			glob := extensionGlob(m.policyGlob.Value())
+			if _, err := path.Match(glob, ""); err != nil {
+				return m, m.showFlash(fmt.Sprintf("Invalid glob %q", glob), 2*time.Second)
+			}
+			m.policyGlobMode = false
+			m.policyGlob.Blur()
+			return m, m.applyBulkPolicy("matching "+glob, func(item queue.Item) bool {
+				ok, _ := path.Match(glob, strings.ToLower(item.Name))
+				return ok
+			})
+		}
+		var cmd tea.Cmd
+		m.policyGlob, cmd = m.policyGlob.Update(msg)
+		return m, cmd
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Conflict):
+		m.bulkPolicy = (m.bulkPolicy + 1) % (queue.PolicyRenameNew + 1)
+	case msg.String() == "a":
+		return m, m.applyBulkPolicy("", func(queue.Item) bool { return true })
+	case msg.String() == "s":
+		selected := make(map[string]bool)
+		items := m.queue.Items()
+		indices := m.queueMatchIndices()
+		if len(indices) == 0 {
+			indices = []int{m.selectedIndex}
+		}
+		for _, i := range indices {
+			if i >= 0 && i < len(items) {
+				selected[items[i].Remote+":"+items[i].Path] = true
+			}
+		}
+		return m, m.applyBulkPolicy("selected", func(item queue.Item) bool {
+			return selected[item.Remote+":"+item.Path]
+		})
+	case msg.String() == "e":
+		m.policyGlobMode = true
+		m.policyGlob.SetValue("")
+		m.policyGlob.Focus()
+		return m, textinput.Blink
+	case key.Matches(msg, m.keys.Escape):
+		m.policyMenu = false
+	}
+	return m, nil
+}
+
+// applyBulkPolicy sets the chosen policy on the queue items matching filter
+// and closes the menu; which describes them in the confirmation
+func (m *Model) applyBulkPolicy(which string, filter func(queue.Item) bool) tea.Cmd {
+	m.policyMenu = false
+	n := m.queue.BulkSetConflictPolicy(m.bulkPolicy, filter)
+	if which != "" {
+		which += " "
+	}
+	return m.showFlash(fmt.Sprintf("Set %s on %d %sitems", m.bulkPolicy.Badge(), n, which), 2*time.Second)
+}
+
+// extensionGlob lowercases a glob typed in the bulk policy menu, turning a
+// bare extension such as "mkv" or ".mkv" into "*.mkv"
+func extensionGlob(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if !strings.ContainsAny(s, "*?[") {
+		s = "*." + strings.TrimPrefix(s, ".")
+	}
+	return s
+}
+
+// bulkPolicyView renders the bulk policy menu above the queue
+func (m Model) bulkPolicyView() string {
+	if m.policyGlobMode {
+		return filterPromptStyle.Render(m.policyGlob.View()) + "\n" +
+			helpStyle.Render(fmt.Sprintf("names matching the glob get %s • enter: apply • esc: back", m.bulkPolicy.Badge()))
+	}
+	// Selected means the search matches, or else the highlighted item
+	selected := "[s]elected"
+	if n := len(m.queueMatchIndices()); n > 0 {
+		selected = fmt.Sprintf("[s] %d matches", n)
+	}
+	return filterPromptStyle.Render(fmt.Sprintf("Set policy %s for: [a]ll %s [e]xtension", m.bulkPolicy.Badge(), selected)) + "\n" +
+		helpStyle.Render("c: change policy • esc: cancel")
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"rcloneb/rclone"
+)
+
+// changesLoadedMsg is sent when the current path has been compared with its
+// previous listing
+type changesLoadedMsg struct {
+	remote, path             string
+	added, modified, deleted []rclone.FileItem
+	err                      error
+}
+
+// listingKey returns the key of the current path in Model.listings
+func (m Model) listingKey() string {
+	return m.currentRemote + ":" + m.currentPath
+}
+
+// fullListing reports whether the shown listing is unfiltered, so it can be
+// kept as a snapshot to compare later listings with
+func (m Model) fullListing() bool {
+	return !m.showRecent && !m.hideSmall && !m.flatList && !m.dateFiltered() && m.listMode == rclone.ListAll
+}
+
+// showChanges returns a command that re-lists the current path and compares
+// it with the previous listing
+func (m *Model) showChanges() tea.Cmd {
+	snapshot, ok := m.listings[m.listingKey()]
+	if !ok || !m.fullListing() {
+		return m.showFlash("No earlier listing of this directory to compare with", 2*time.Second)
+	}
+	remote, path := m.currentRemote, m.currentPath
+	return func() tea.Msg {
+		added, modified, deleted, err := rclone.ListFilesChangedSince(context.Background(), remote, path, snapshot)
+		return changesLoadedMsg{remote: remote, path: path, added: added, modified: modified, deleted: deleted, err: err}
+	}
+}
+
+// applyChanges highlights the changed files and reloads the listing to show them
+func (m Model) applyChanges(msg changesLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		return m, m.showFlash("Failed to list changes: "+msg.err.Error(), 3*time.Second)
+	}
+	// Ignore changes for a directory we have since left
+	if msg.remote != m.currentRemote || msg.path != m.currentPath {
+		return m, nil
+	}
+
+	m.changes = make(map[string]string, len(msg.added)+len(msg.modified))
+	for _, f := range msg.added {
+		m.changes[f.Path] = "added"
+	}
+	for _, f := range msg.modified {
+		m.changes[f.Path] = "modified"
+	}
+	m.changesKey = m.listingKey()
+
+	summary := fmt.Sprintf("%d added, %d modified, %d deleted since the last listing", len(msg.added), len(msg.modified), len(msg.deleted))
+	if len(m.changes) == 0 && len(msg.deleted) == 0 {
+		return m, m.showFlash(summary, 3*time.Second)
+	}
+	m.loading = true
+	return m, tea.Batch(m.reloadFiles(), m.spinner.Tick, m.showFlash(summary, 3*time.Second))
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"rcloneb/queue"
+	"rcloneb/rclone"
+)
+
+// Config holds user-tunable application settings
+type Config struct {
+	// ShowThumbnails renders image previews in the file browser when the
+	// terminal supports an inline graphics protocol
+	ShowThumbnails bool
+
+	// NoIcons replaces emoji markers with plain-text labels
+	NoIcons bool
+
+	// CacheTTL is how long directory listings are reused before re-listing;
+	// zero disables the cache
+	CacheTTL time.Duration
+
+	// ThemePath is the JSON theme file loaded at startup and on SIGHUP
+	ThemePath string
+
+	// ServeAddr is the listen address used when serving a remote
+	ServeAddr string
+
+	// S3Addr is the listen address used when serving a remote over S3, with
+	// the keys clients must use; empty keys are generated per session
+	S3Addr      string
+	S3AccessKey string
+	S3SecretKey string
+
+	// FTPAddr is the listen address used when serving a remote over FTP, with
+	// the login clients must use; an empty password is generated per session
+	FTPAddr string
+	FTPUser string
+	FTPPass string
+
+	// ResticAddr is the listen address used when serving a remote as a restic
+	// REST backend; ResticAppendOnly stops clients deleting or changing data
+	ResticAddr       string
+	ResticAppendOnly bool
+
+	// CreateEmptyDirs recreates empty source subdirectories when copying a directory
+	CreateEmptyDirs bool
+
+	// IgnoreExisting skips files that already exist locally, whatever their
+	// size or modification time
+	IgnoreExisting bool
+
+	// CompressUploads gzips files written to remotes, adding ".gz" to their names
+	CompressUploads bool
+
+	// SkipNewerAtDest only downloads files that are newer on the remote than
+	// their local copy
+	SkipNewerAtDest bool
+
+	// NoCheckDest skips reading back the destination, for write-once storage
+	NoCheckDest bool
+
+	// DriveStopOnLimit stops Google Drive downloads at the daily download
+	// limit; they are then retried after a pause
+	DriveStopOnLimit bool
+
+	// SFTPConcurrency is the number of requests kept in flight for each file
+	// transferred to or from an SFTP remote
+	SFTPConcurrency int
+
+	// LowConcurrencyMode runs one transfer and one checker at a time, and
+	// pauses between the files of a batch, for remotes such as Mega and Box
+	// that throttle parallel requests. Transfers switch to it by themselves
+	// after repeated 429 or 503 errors.
+	LowConcurrencyMode bool
+
+	// WriteChecksumFile writes an MD5 file beside every downloaded file
+	WriteChecksumFile bool
+
+	// CutoffMode is rclone's --cutoff-mode, how transfers stop once a
+	// --max-transfer limit is reached; empty keeps rclone's default
+	CutoffMode string
+
+	// Immutable fails downloads that would overwrite a different local file
+	// instead of replacing it
+	Immutable bool
+
+	// PreserveMetadata copies file metadata such as modification times (rclone 1.59+)
+	PreserveMetadata bool
+
+	// UseRC starts transfers with rclone's remote control API enabled on RCAddr
+	// and polls it for live global statistics
+	UseRC  bool
+	RCAddr string
+
+	// BatchSize starts downloads automatically once the queue holds this many
+	// items; zero disables auto-start
+	BatchSize int
+
+	// Retry controls how transfers are retried after a transient error
+	Retry rclone.RetryConfig
+
+	// Verbose writes diagnostics such as skipped listing entries to LogPath
+	Verbose bool
+	LogPath string
+
+	// Excludes are rclone filter patterns skipped, and deleted locally, when
+	// mirroring a directory
+	Excludes []string
+
+	// FastListing searches subdirectories too when filtering, using the
+	// name-only "rclone ls" listing
+	FastListing bool
+
+	// FastList lists S3 remotes with rclone's --fast-list
+	FastList bool
+
+	// FetchChecksums lists every file's hashes along with the directory, so
+	// they are cached for verifying downloads
+	FetchChecksums bool
+
+	// ExpandDirs queues the files inside a directory rather than the directory
+	// itself, descending at most MaxQueueDepth levels
+	ExpandDirs    bool
+	MaxQueueDepth int
+
+	// QueueSaveInterval is how long queue changes are collected before the
+	// queue is written to disk
+	QueueSaveInterval time.Duration
+
+	// MinSizeFilter is the smallest file size listed while small files are hidden
+	MinSizeFilter int64
+
+	// SortOrder sorts directory listings after rclone lists them, such as
+	// "size,desc"; empty keeps rclone's name order
+	SortOrder string
+
+	// DecryptNames shows the decrypted names of files stored by a crypt remote
+	// when browsing the remote it wraps
+	DecryptNames bool
+
+	// SkipUpdateCheck stops rcloneb asking rclone at startup whether a newer
+	// rclone release is available
+	SkipUpdateCheck bool
+
+	// StartPath is an optional "remote:path" to open directly on launch
+	StartPath string
+}
+
+// dataDir returns the directory rcloneb keeps its state files in,
+// $XDG_DATA_HOME/rcloneb or ~/.local/share/rcloneb
+func dataDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "rcloneb")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "share", "rcloneb")
+}
+
+// DefaultConfig returns the default application settings
+func DefaultConfig() Config {
+	return Config{
+		ShowThumbnails:  false,
+		NoIcons:         false,
+		CacheTTL:        30 * time.Second,
+		ThemePath:       defaultThemePath(),
+		ServeAddr:       "127.0.0.1:8080",
+		S3Addr:          "127.0.0.1:8081",
+		FTPAddr:         "127.0.0.1:2121",
+		ResticAddr:      "127.0.0.1:8082",
+		MinSizeFilter:   1 << 20,
+		MaxQueueDepth:   queue.DefaultMaxDepth,
+		FTPUser:         "rcloneb",
+		CreateEmptyDirs: false,
+		IgnoreExisting:  false,
+		UseRC:           false,
+		RCAddr:          "127.0.0.1:5572",
+		BatchSize:       0,
+		Retry:           rclone.DefaultRetryConfig(),
+		Verbose:         false,
+		LogPath:         filepath.Join(os.TempDir(), "rcloneb.log"),
+
+		QueueSaveInterval: queue.DefaultPersistInterval,
+		SFTPConcurrency:   64,
+	}
+}
+
+// copyOptions returns the rclone copy flags implied by the settings
+func (c Config) copyOptions() rclone.CopyOptions {
+	opts := rclone.CopyOptions{
+		CreateEmptyDirs:   c.CreateEmptyDirs,
+		IgnoreExisting:    c.IgnoreExisting,
+		PreserveMetadata:  c.PreserveMetadata,
+		SkipNewerAtDest:   c.SkipNewerAtDest,
+		Immutable:         c.Immutable,
+		CutoffMode:        c.CutoffMode,
+		WriteChecksumFile: c.WriteChecksumFile,
+		DriveStopOnLimit:  c.DriveStopOnLimit,
+		NoCheckDest:       c.NoCheckDest,
+		SFTPConcurrency:   c.SFTPConcurrency,
+		LowConcurrency:    c.LowConcurrencyMode,
+		Retry:             c.Retry,
+	}
+	if c.UseRC {
+		opts.RCAddr = c.RCAddr
+	}
+	return opts
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"rcloneb/rclone"
+)
+
+// detectedCredential is a set of existing credentials that can pre-fill the new remote form
+type detectedCredential struct {
+	Label   string
+	Name    string // Suggested remote name
+	Type    string
+	Options map[string]string
+}
+
+// detectCredentials scans well-known locations for credentials usable by a new remote
+func detectCredentials() []detectedCredential {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	var found []detectedCredential
+	found = append(found, detectAWSCredentials(home)...)
+	found = append(found, detectGCloudCredentials(home)...)
+	found = append(found, detectRcloneRemotes(home)...)
+	return found
+}
+
+// detectAWSCredentials returns one S3 credential per profile in ~/.aws/credentials
+func detectAWSCredentials(home string) []detectedCredential {
+	profiles, err := readINI(filepath.Join(home, ".aws", "credentials"))
+	if err != nil {
+		return nil
+	}
+
+	// Regions live in ~/.aws/config under "profile <name>" sections
+	config, _ := readINI(filepath.Join(home, ".aws", "config"))
+
+	var found []detectedCredential
+	for _, name := range sortedKeys(profiles) {
+		p := profiles[name]
+		if p["aws_access_key_id"] == "" {
+			continue
+		}
+
+		opts := map[string]string{
+			"provider":          "AWS",
+			"access_key_id":     p["aws_access_key_id"],
+			"secret_access_key": p["aws_secret_access_key"],
+		}
+		if token := p["aws_session_token"]; token != "" {
+			opts["session_token"] = token
+		}
+		section := "profile " + name
+		if name == "default" {
+			section = "default"
+		}
+		if region := config[section]["region"]; region != "" {
+			opts["region"] = region
+		}
+
+		suggested := "s3"
+		if name != "default" {
+			suggested = "s3-" + name
+		}
+		found = append(found, detectedCredential{
+			Label:   "AWS profile " + name + " (~/.aws/credentials)",
+			Name:    suggested,
+			Type:    "s3",
+			Options: opts,
+		})
+	}
+	return found
+}
+
+// detectGCloudCredentials reports a Google Cloud Storage remote when the gcloud
+// CLI is logged in; rclone picks the credentials up through env_auth
+func detectGCloudCredentials(home string) []detectedCredential {
+	dir := filepath.Join(home, ".config", "gcloud")
+	for _, name := range []string{"credentials.db", "application_default_credentials.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return []detectedCredential{{
+				Label:   "gcloud login (~/.config/gcloud/" + name + ")",
+				Name:    "gcs",
+				Type:    "google cloud storage",
+				Options: map[string]string{"env_auth": "true"},
+			}}
+		}
+	}
+	return nil
+}
+
+// detectRcloneRemotes offers each remote in an existing rclone.conf as a template
+func detectRcloneRemotes(home string) []detectedCredential {
+	if _, err := os.Stat(filepath.Join(home, ".config", "rclone", "rclone.conf")); err != nil {
+		return nil
+	}
+	remotes, err := rclone.RemoteConfigs()
+	if err != nil {
+		return nil
+	}
+
+	var found []detectedCredential
+	for _, name := range sortedKeys(remotes) {
+		opts := make(map[string]string)
+		for k, v := range remotes[name] {
+			if k != "type" {
+				opts[k] = v
+			}
+		}
+		found = append(found, detectedCredential{
+			Label:   "Existing remote " + name + " (rclone.conf)",
+			Name:    name + "-copy",
+			Type:    remotes[name]["type"],
+			Options: opts,
+		})
+	}
+	return found
+}
+
+// readINI parses a simple INI file into section -> key -> value
+func readINI(path string) (map[string]map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sections := make(map[string]map[string]string)
+	var current map[string]string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "", strings.HasPrefix(line, "#"), strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			current = make(map[string]string)
+			sections[name] = current
+		case current != nil:
+			if k, v, ok := strings.Cut(line, "="); ok {
+				current[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			}
+		}
+	}
+	return sections, scanner.Err()
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"rcloneb/rclone"
+)
+
+// dateLayout is the format of the date range inputs
+const dateLayout = "2006-01-02"
+
+// newDateInputs creates the from and to inputs of the date range filter
+func newDateInputs() []textinput.Model {
+	from := textinput.New()
+	from.Prompt = "from: "
+	from.Placeholder = "YYYY-MM-DD"
+	from.CharLimit = len(dateLayout)
+
+	to := textinput.New()
+	to.Prompt = "to: "
+	to.Placeholder = "YYYY-MM-DD"
+	to.CharLimit = len(dateLayout)
+
+	return []textinput.Model{from, to}
+}
+
+// openDateFilter shows the date range inputs, filled with the active range
+func (m *Model) openDateFilter() tea.Cmd {
+	m.dateMode = true
+	m.dateFocus = 0
+	for i, t := range []time.Time{m.dateFrom, m.dateTo} {
+		m.dateInputs[i].SetValue("")
+		if !t.IsZero() {
+			m.dateInputs[i].SetValue(t.Format(dateLayout))
+		}
+		m.dateInputs[i].Blur()
+	}
+	m.dateInputs[0].Focus()
+	return textinput.Blink
+}
+
+// updateDateFilter handles the date range inputs. Enter on the from date moves
+// to the to date; enter on the to date applies the range. Either end may be
+// left empty, and clearing both removes the filter.
+func (m Model) updateDateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.dateMode = false
+		m.dateInputs[m.dateFocus].Blur()
+		return m, nil
+	case "tab", "shift+tab":
+		m.dateInputs[m.dateFocus].Blur()
+		m.dateFocus = 1 - m.dateFocus
+		m.dateInputs[m.dateFocus].Focus()
+		return m, textinput.Blink
+	case "enter":
+		if m.dateFocus == 0 {
+			m.dateInputs[0].Blur()
+			m.dateFocus = 1
+			m.dateInputs[1].Focus()
+			return m, textinput.Blink
+		}
+
+		from, err := parseDate(m.dateInputs[0].Value())
+		if err != nil {
+			return m, m.showFlash(err.Error(), 3*time.Second)
+		}
+		to, err := parseDate(m.dateInputs[1].Value())
+		if err != nil {
+			return m, m.showFlash(err.Error(), 3*time.Second)
+		}
+		if !from.IsZero() && !to.IsZero() && to.Before(from) {
+			return m, m.showFlash("The to date is before the from date", 3*time.Second)
+		}
+
+		m.dateMode = false
+		m.dateInputs[1].Blur()
+		m.dateFrom, m.dateTo = from, to
+		if m.dateFiltered() {
+			// The listing takes one filter at a time
+			m.showRecent = false
+			m.hideSmall = false
+			m.flatList = false
+			m.listMode = rclone.ListAll
+		}
+		m.fileIndex = 0
+		m.loading = true
+		return m, tea.Batch(m.reloadFiles(), m.spinner.Tick)
+	}
+
+	var cmd tea.Cmd
+	m.dateInputs[m.dateFocus], cmd = m.dateInputs[m.dateFocus].Update(msg)
+	return m, cmd
+}
+
+// parseDate parses a YYYY-MM-DD date in local time; empty input is the zero time
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.ParseInLocation(dateLayout, s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+	}
+	return t, nil
+}
+
+// dateFiltered reports whether the listing is limited to a date range
+func (m Model) dateFiltered() bool {
+	return !m.dateFrom.IsZero() || !m.dateTo.IsZero()
+}
+
+// dateFilterLabel describes the active date range for the header
+func (m Model) dateFilterLabel() string {
+	from, to := "…", "…"
+	if !m.dateFrom.IsZero() {
+		from = m.dateFrom.Format(dateLayout)
+	}
+	if !m.dateTo.IsZero() {
+		to = m.dateTo.Format(dateLayout)
+	}
+	return "[" + from + " → " + to + "]"
+}
+
+// dateFilterPromptView renders the date range inputs
+func (m Model) dateFilterPromptView() string {
+	var b strings.Builder
+	b.WriteString(filterPromptStyle.Render(m.dateInputs[0].View()))
+	b.WriteString("  ")
+	b.WriteString(filterPromptStyle.Render(m.dateInputs[1].View()))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("modified between these dates, either may be empty • tab: switch • enter: apply • esc: cancel"))
+	return b.String()
+}
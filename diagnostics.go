@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"rcloneb/rclone"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// diagnosticsMsg carries the results of diagnosing a remote
+type diagnosticsMsg struct {
+	remote  string
+	results []rclone.DiagResult
+}
+
+// openDiagnostics shows the diagnostics view and starts testing remotes,
+// all in parallel
+func (m *Model) openDiagnostics(remotes ...string) tea.Cmd {
+	m.state = StateDiagnostics
+	m.diagRemotes = remotes
+	m.diagResults = make(map[string][]rclone.DiagResult)
+	m.loading = true
+	cmds := []tea.Cmd{m.spinner.Tick}
+	for _, remote := range remotes {
+		cmds = append(cmds, runDiagnostics(remote))
+	}
+	return tea.Batch(cmds...)
+}
+
+// runDiagnostics returns a command that runs the connectivity tests on remote
+func runDiagnostics(remote string) tea.Cmd {
+	return func() tea.Msg {
+		results, _ := rclone.DiagnoseRemote(context.Background(), remote)
+		return diagnosticsMsg{remote: remote, results: results}
+	}
+}
+
+// updateDiagnostics handles input in the diagnostics view
+func (m Model) updateDiagnostics(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Refresh):
+		if !m.loading {
+			return m, m.openDiagnostics(m.diagRemotes...)
+		}
+	case key.Matches(msg, m.keys.Escape), key.Matches(msg, m.keys.Back), msg.String() == "q":
+		m.state = StateRemoteSelect
+		m.loading = false
+	}
+	return m, nil
+}
+
+// diagnosticsView renders the results of diagnosing one remote, or a table
+// of results when several were diagnosed together
+func (m Model) diagnosticsView() string {
+	var b strings.Builder
+
+	title := "Diagnostics: " + strings.Join(m.diagRemotes, ", ")
+	if len(m.diagRemotes) > 1 {
+		title = fmt.Sprintf("Diagnostics: %d remotes", len(m.diagRemotes))
+	}
+	b.WriteString(titleStyle.Render(title))
+	b.WriteString("\n\n")
+
+	if m.loading {
+		b.WriteString(m.spinner.View())
+		b.WriteString(" Running tests...\n")
+	}
+	if len(m.diagRemotes) == 1 {
+		for _, r := range m.diagResults[m.diagRemotes[0]] {
+			icon, style := "✓", successStyle
+			if r.Status != rclone.DiagPass {
+				icon, style = "✗", errorStyle
+			}
+			b.WriteString(style.Render(fmt.Sprintf(" %s %-12s", icon, r.Test)))
+			b.WriteString(" " + r.Detail)
+			b.WriteString("\n")
+		}
+	} else {
+		b.WriteString(m.diagnosticsTable())
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("r: run again • esc: back"))
+	return b.String()
+}
+
+// diagnosticsTable renders one row of test outcomes per diagnosed remote,
+// followed by the details of every failed test
+func (m Model) diagnosticsTable() string {
+	var b strings.Builder
+
+	width := 12
+	for _, remote := range m.diagRemotes {
+		width = max(width, len(remote))
+	}
+
+	var header strings.Builder
+	fmt.Fprintf(&header, " %-*s", width, "Remote")
+	for _, r := range m.diagResults[m.diagRemotes[0]] {
+		fmt.Fprintf(&header, " %-12s", r.Test)
+	}
+	if len(m.diagResults[m.diagRemotes[0]]) > 0 {
+		b.WriteString(headerStyle.Render(header.String()))
+		b.WriteString("\n")
+	}
+
+	var failures []string
+	for _, remote := range m.diagRemotes {
+		b.WriteString(fmt.Sprintf(" %-*s", width, remote))
+		results, ok := m.diagResults[remote]
+		if !ok {
+			b.WriteString(" " + bannerStyle.Render("testing..."))
+		}
+		for _, r := range results {
+			cell := successStyle.Render(fmt.Sprintf(" %-12s", "✓"))
+			if r.Status != rclone.DiagPass {
+				cell = errorStyle.Render(fmt.Sprintf(" %-12s", "✗"))
+				failures = append(failures, fmt.Sprintf(" %s %s: %s", remote, r.Test, r.Detail))
+			}
+			b.WriteString(cell)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(failures) > 0 {
+		b.WriteString("\n")
+		for _, f := range failures {
+			b.WriteString(errorStyle.Render(f))
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"rcloneb/rclone"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// extractUpdate reports progress of a running archive extraction
+type extractUpdate struct {
+	done     int64
+	total    int64
+	finished bool
+	err      error
+}
+
+// extractMsg carries an extraction update and the channel to wait on for the next one
+type extractMsg struct {
+	update extractUpdate
+	ch     <-chan extractUpdate
+}
+
+// extractState tracks the archive being extracted
+type extractState struct {
+	name  string
+	dest  string
+	done  int64
+	total int64
+}
+
+// isZipFile reports whether the file name has a .zip extension
+func isZipFile(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".zip")
+}
+
+// startExtract downloads and unpacks a zip archive into a directory named after
+// it in the download directory
+func (m *Model) startExtract(f BrowserItem) tea.Cmd {
+	dest := filepath.Join(downloadDir(), strings.TrimSuffix(f.Name, filepath.Ext(f.Name)))
+	m.extract = &extractState{name: f.Name, dest: dest, total: f.Size}
+
+	remote := m.currentRemote
+	ch := make(chan extractUpdate, 1)
+	go func() {
+		defer close(ch)
+		err := rclone.ExtractToLocalWithProgress(context.Background(), remote, f.Path, dest, f.Size, func(done, total int64) {
+			// Drop updates the UI has not caught up with yet
+			select {
+			case ch <- extractUpdate{done: done, total: total}:
+			default:
+			}
+		})
+		ch <- extractUpdate{finished: true, err: err}
+	}()
+	return waitForExtract(ch)
+}
+
+// waitForExtract returns a command that waits for the next extraction update
+func waitForExtract(ch <-chan extractUpdate) tea.Cmd {
+	return func() tea.Msg {
+		u, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return extractMsg{update: u, ch: ch}
+	}
+}
+
+// handleExtract applies an extraction update to the model
+func (m *Model) handleExtract(msg extractMsg) tea.Cmd {
+	if m.extract == nil {
+		return nil
+	}
+	if !msg.update.finished {
+		m.extract.done = msg.update.done
+		return waitForExtract(msg.ch)
+	}
+
+	name, dest := m.extract.name, m.extract.dest
+	m.extract = nil
+	if msg.update.err != nil {
+		m.err = msg.update.err
+		return nil
+	}
+	return m.showFlash(fmt.Sprintf("Extracted %s to %s", name, dest), 3*time.Second)
+}
+
+// extractIndicator returns the browser status text for a running extraction
+func (m Model) extractIndicator() string {
+	e := m.extract
+	if e.total <= 0 {
+		return fmt.Sprintf("[Extracting %s: %s]", e.name, rclone.FormatSize(e.done))
+	}
+	return fmt.Sprintf("[Extracting %s: %d%%]", e.name, e.done*100/e.total)
+}
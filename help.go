@@ -0,0 +1,205 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Help screens, in markdown, for each view
+const (
+	remoteSelectHelp = "# Remotes\n\n" +
+		"- `j`/`k`: move between remotes\n" +
+		"- `enter`/`l`: browse the selected remote\n" +
+		"- `n`: create a new remote\n" +
+		"- `ctrl+d`: clone the selected remote under a new name\n" +
+		"- `u`: export the disk usage of the selected remote\n" +
+		"- `U`: unmount rclone mounts that no longer respond, after a y/N\n" +
+		"- `d`: test whether the selected remote can be listed, sized and asked for its quota\n" +
+		"- `E`: enable a remote marked [disabled] in the rclone config\n" +
+		"- `v`: mark several remotes with `space`, then `ctrl+d` diagnoses them or `ctrl+a` sums up their quotas\n" +
+		"- `,`: settings\n" +
+		"- `ctrl+p`: command palette\n" +
+		"- `q`: quit\n"
+
+	fileBrowserHelp = "# File browser\n\n" +
+		"## Moving\n\n" +
+		"- `j`/`k`: move the cursor\n" +
+		"- `l`/`enter`: open a directory, or queue a file\n" +
+		"- `h`/`backspace`: go up a directory\n\n" +
+		"## Selecting\n\n" +
+		"- `space`: select the file under the cursor\n" +
+		"- `a`: select everything\n" +
+		"- `v`, `J`/`K`: select a range\n" +
+		"- `q`: queue the selection and open the queue\n\n" +
+		"## Listing\n\n" +
+		"- `/`: filter by name\n" +
+		"- `r`: refresh, bypassing the cache\n" +
+		"- `o`: change the sort order\n" +
+		"- `tab`: cycle files only, directories only and everything\n" +
+		"- `R`: recent files, `S`: hide small files, `L`: flat listing, `D`: date range\n" +
+		"- `ctrl+r`: highlight changes since the last listing\n" +
+		"- `ctrl+d`: show decrypted names behind a crypt remote\n\n" +
+		"## Files\n\n" +
+		"- `i`: details, where `c` computes checksums\n" +
+		"- `p`: view the file\n" +
+		"- `ctrl+y`: copy the remote path\n" +
+		"- `ctrl+v`: paste the clipboard into a new file\n" +
+		"- `X`: extract a zip archive\n" +
+		"- `M`: mirror a directory\n" +
+		"- `P`: purge a directory\n\n" +
+		"## Serving\n\n" +
+		"- `ctrl+w`: WebDAV, `ctrl+s`: S3, `F`: FTP\n"
+
+	queueHelp = "# Queue\n\n" +
+		"- `j`/`k`: move between items\n" +
+		"- `d`/`x`: remove the item, or every search match\n" +
+		"- `/`: find items; `tag:name` matches tags\n" +
+		"- `t`: edit the item's tags\n" +
+		"- `p`: pause or resume the item\n" +
+		"- `c`: cycle what happens when the file exists\n" +
+		"- `P`: set that for every item, the search matches, or the names matching a glob\n" +
+		"- `C`: clear completed items\n" +
+		"- `I`: queue files from a list\n" +
+		"- `ctrl+d`: change the download directory\n" +
+		"- `s`: preview and start the download\n" +
+		"- `esc`: back to the browser\n"
+
+	queuePreviewHelp = "# Download preview\n\n" +
+		"- `j`/`k`: scroll the files that will be copied\n" +
+		"- `enter`: start the download\n" +
+		"- `esc`: back to the queue\n"
+
+	transferHelp = "# Transfers\n\n" +
+		"- `h`/`l`: switch column\n" +
+		"- `j`/`k`: scroll the column\n" +
+		"- `b`: change the bandwidth limit\n" +
+		"- `T`: change the number of parallel transfers\n" +
+		"- `C`: change the number of parallel checkers\n" +
+		"- `enter`: back to browsing once finished\n" +
+		"- `ctrl+c`: cancel and quit\n\n" +
+		"Changing limits needs the remote control setting.\n"
+
+	fileViewerHelp = "# File viewer\n\n" +
+		"- `j`/`k`: scroll\n" +
+		"- `esc`/`h`/`q`: back to the browser\n"
+
+	settingsHelp = "# Settings\n\n" +
+		"- `j`/`k`: move between settings\n" +
+		"- `space`/`enter`: toggle or cycle the setting\n" +
+		"- `esc`: back\n\n" +
+		"Settings without a toggle are set with command-line flags. " +
+		"`[env]` marks settings overridden by an `RCLONE_*` variable.\n"
+
+	mirrorConfirmHelp = "# Mirror\n\n" +
+		"Mirroring makes the local directory match the remote, deleting local files the remote lacks.\n\n" +
+		"- `y`: start mirroring\n" +
+		"- `n`/`esc`: back to the preview\n"
+
+	newRemoteHelp = "# New remote\n\n" +
+		"- `tab`/`↑`/`↓`: move between fields\n" +
+		"- `↑`/`↓` in the type field: pick a backend\n" +
+		"- `enter`: next field, or press the focused button\n" +
+		"- `esc`: cancel\n"
+
+	configWizardHelp = "# Detected credentials\n\n" +
+		"- `j`/`k`: move between credentials\n" +
+		"- `enter`: fill in the new remote with them\n" +
+		"- `esc`: back\n"
+
+	resumePromptHelp = "# Resume\n\n" +
+		"- `y`: resume the interrupted download\n" +
+		"- `n`/`enter`/`esc`: discard it\n"
+
+	authorizingHelp = "# Authorizing\n\n" +
+		"Finish signing in with the provider in your browser.\n\n" +
+		"- `esc`: cancel\n"
+
+	diagnosticsHelp = "# Diagnostics\n\n" +
+		"Each test runs in parallel with a 10 second limit. Marked remotes are\n" +
+		"tested together, one row each, with failures listed below.\n\n" +
+		"- `r`: run the tests again\n" +
+		"- `esc`: back to the remotes\n"
+
+	quotaSummaryHelp = "# Quota summary\n\n" +
+		"Storage used by each marked remote, as reported by `rclone about`.\n" +
+		"The total only adds up remotes that report their size.\n\n" +
+		"- `r`: read the quotas again\n" +
+		"- `esc`: back to the remotes\n"
+
+	defaultHelp = "# Help\n\n" +
+		"- `esc`: cancel\n" +
+		"- `ctrl+c`: quit\n"
+)
+
+// helpForState returns the help screen, in markdown, for a view
+func helpForState(state AppState) string {
+	switch state {
+	case StateRemoteSelect:
+		return remoteSelectHelp
+	case StateFileBrowser:
+		return fileBrowserHelp
+	case StateQueueView:
+		return queueHelp
+	case StateQueuePreview:
+		return queuePreviewHelp
+	case StateTransferView:
+		return transferHelp
+	case StateFileViewer:
+		return fileViewerHelp
+	case StateSettings:
+		return settingsHelp
+	case StateMirrorConfirm:
+		return mirrorConfirmHelp
+	case StateNewRemote:
+		return newRemoteHelp
+	case StateConfigWizard:
+		return configWizardHelp
+	case StateResumePrompt:
+		return resumePromptHelp
+	case StateAuthorizing:
+		return authorizingHelp
+	case StateDiagnostics:
+		return diagnosticsHelp
+	case StateQuotaSummary:
+		return quotaSummaryHelp
+	default:
+		return defaultHelp
+	}
+}
+
+// helpBoxStyle frames the help overlay
+var helpBoxStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
+
+// updateHelp handles input while the help overlay is open
+func (m Model) updateHelp(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "?", "esc":
+		m.showHelp = false
+	}
+	return m, nil
+}
+
+// helpOverlay draws the help for the current view over base, centered
+func (m Model) helpOverlay(base string) string {
+	box := helpBoxStyle.Render(renderMarkdown(helpForState(m.state), m.width-4) + "\n" + helpStyle.Render("?/esc: close"))
+	return m.overlay(base, box)
+}
+
+// overlay draws box centered over the rendered view base
+func (m Model) overlay(base, box string) string {
+	lines := strings.Split(base, "\n")
+	boxLines := strings.Split(box, "\n")
+	for len(lines) < max(m.height, len(boxLines)) {
+		lines = append(lines, "")
+	}
+
+	// Box lines replace whole view lines; cutting styled lines is not worth it
+	top := max((len(lines)-len(boxLines))/2, 0)
+	left := strings.Repeat(" ", max((m.width-lipgloss.Width(box))/2, 0))
+	for i, line := range boxLines {
+		lines[top+i] = left + line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,29 @@
+//go:build glamour
+
+// Help screens rendered with glamour instead of the built-in renderer.
+//
+// glamour is not a default dependency, so fetch it before building:
+//
+//	go get github.com/charmbracelet/glamour@latest
+//	go build -tags glamour .
+
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+)
+
+// renderMarkdown renders the markdown of a help screen for the terminal
+func renderMarkdown(md string, width int) string {
+	r, err := glamour.NewTermRenderer(glamour.WithAutoStyle(), glamour.WithWordWrap(width))
+	if err != nil {
+		return md
+	}
+	out, err := r.Render(md)
+	if err != nil {
+		return md
+	}
+	return strings.Trim(out, "\n")
+}
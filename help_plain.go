@@ -0,0 +1,34 @@
+//go:build !glamour
+
+package main
+
+import "strings"
+
+// renderMarkdown styles the small subset of markdown the help screens use:
+// headings, bullet lists and `code` spans
+func renderMarkdown(md string, width int) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(md, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "# "):
+			b.WriteString(titleStyle.Render(strings.TrimPrefix(line, "# ")))
+		case strings.HasPrefix(line, "## "):
+			b.WriteString(headerStyle.Render(strings.TrimPrefix(line, "## ")))
+		case strings.HasPrefix(line, "- "):
+			b.WriteString("  • " + renderCode(strings.TrimPrefix(line, "- ")))
+		default:
+			b.WriteString(renderCode(line))
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// renderCode highlights the `code` spans of a line
+func renderCode(line string) string {
+	parts := strings.Split(line, "`")
+	for i := 1; i < len(parts); i += 2 {
+		parts[i] = cursorStyle.Render(parts[i])
+	}
+	return strings.Join(parts, "")
+}
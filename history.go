@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// historyPath returns the location of the history log
+func historyPath() string {
+	return filepath.Join(dataDir(), "history.log")
+}
+
+// appendHistory adds a timestamped event to the history log
+func appendHistory(event string) error {
+	p := historyPath()
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open history log: %w", err)
+	}
+	if _, err := fmt.Fprintf(f, "%s %s\n", time.Now().Format(time.RFC3339), event); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write history log: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"log"
+	"time"
+)
+
+// logHook writes transfer events to the --verbose log
+type logHook struct{}
+
+// OnStart implements rclone.TransferHook
+func (logHook) OnStart(id, src, dst string) {
+	log.Printf("%s: copying %s to %s", id, src, dst)
+}
+
+// OnProgress implements rclone.TransferHook; progress is too frequent to log
+func (logHook) OnProgress(id string, pct float64, bytesCopied, bytesTotal int64) {}
+
+// OnComplete implements rclone.TransferHook
+func (logHook) OnComplete(id string, duration time.Duration) {
+	log.Printf("%s: completed in %v", id, duration.Round(time.Millisecond))
+}
+
+// OnFail implements rclone.TransferHook
+func (logHook) OnFail(id string, err error) {
+	log.Printf("%s: failed: %v", id, err)
+}
@@ -4,22 +4,68 @@ import "github.com/charmbracelet/bubbles/key"
 
 // KeyMap defines all keybindings for the application
 type KeyMap struct {
-	Up       key.Binding
-	Down     key.Binding
-	Left     key.Binding
-	Right    key.Binding
-	Enter    key.Binding
-	Back     key.Binding
-	Select   key.Binding
-	SelectAll key.Binding
-	Queue    key.Binding
-	Filter   key.Binding
-	Escape   key.Binding
-	Quit     key.Binding
-	Help     key.Binding
-	Start    key.Binding
-	Remove   key.Binding
-	Refresh  key.Binding
+	Up           key.Binding
+	Down         key.Binding
+	Left         key.Binding
+	Right        key.Binding
+	Enter        key.Binding
+	Back         key.Binding
+	Select       key.Binding
+	SelectAll    key.Binding
+	Queue        key.Binding
+	Filter       key.Binding
+	Escape       key.Binding
+	Quit         key.Binding
+	Help         key.Binding
+	Start        key.Binding
+	Remove       key.Binding
+	Refresh      key.Binding
+	Info         key.Binding
+	Checksum     key.Binding
+	Preview      key.Binding
+	Palette      key.Binding
+	WebDAV       key.Binding
+	Range        key.Binding
+	RangeUp      key.Binding
+	RangeDown    key.Binding
+	Settings     key.Binding
+	Find         key.Binding
+	Mirror       key.Binding
+	NewRemote    key.Binding
+	Recent       key.Binding
+	FlatList     key.Binding
+	DateRange    key.Binding
+	ListMode     key.Binding
+	ShowChanges  key.Binding
+	ServeS3      key.Binding
+	ServeFTP     key.Binding
+	ChangeDest   key.Binding
+	Yank         key.Binding
+	Purge        key.Binding
+	DecryptNames key.Binding
+	Sort         key.Binding
+	HideSmall    key.Binding
+	Paste        key.Binding
+	Extract      key.Binding
+	DiskUsage    key.Binding
+	Tag          key.Binding
+	Import       key.Binding
+	Clone        key.Binding
+	BwLimit      key.Binding
+	Transfers    key.Binding
+	Checkers     key.Binding
+	UnmountStale key.Binding
+	Conflict     key.Binding
+	BulkPolicy   key.Binding
+	ClearDone    key.Binding
+	Pause        key.Binding
+	EnableRemote key.Binding
+	Diagnose     key.Binding
+
+	// Multi-select mode in the remote list
+	MarkRemotes    key.Binding
+	DiagnoseMarked key.Binding
+	QuotaSummary   key.Binding
 }
 
 // DefaultKeyMap returns the default keybindings
@@ -89,6 +135,185 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("r"),
 			key.WithHelp("r", "refresh"),
 		),
+		Info: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "file details"),
+		),
+		Checksum: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "compute checksum"),
+		),
+		Preview: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "view file"),
+		),
+		Palette: key.NewBinding(
+			key.WithKeys("ctrl+p"),
+			key.WithHelp("ctrl+p", "command palette"),
+		),
+		WebDAV: key.NewBinding(
+			key.WithKeys("ctrl+w"),
+			key.WithHelp("ctrl+w", "serve over WebDAV"),
+		),
+		ServeS3: key.NewBinding(
+			key.WithKeys("ctrl+s"),
+			key.WithHelp("ctrl+s", "serve as S3"),
+		),
+		ServeFTP: key.NewBinding(
+			key.WithKeys("F"),
+			key.WithHelp("F", "serve over FTP"),
+		),
+		ChangeDest: key.NewBinding(
+			key.WithKeys("ctrl+d"),
+			key.WithHelp("ctrl+d", "change destination"),
+		),
+		Yank: key.NewBinding(
+			key.WithKeys("ctrl+y"),
+			key.WithHelp("ctrl+y", "copy remote path"),
+		),
+		HideSmall: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "hide small files"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "change sort order"),
+		),
+		DecryptNames: key.NewBinding(
+			key.WithKeys("ctrl+d"),
+			key.WithHelp("ctrl+d", "decrypt names"),
+		),
+		Purge: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "purge directory"),
+		),
+		Paste: key.NewBinding(
+			key.WithKeys("ctrl+v"),
+			key.WithHelp("ctrl+v", "paste"),
+		),
+		Extract: key.NewBinding(
+			key.WithKeys("X"),
+			key.WithHelp("X", "extract zip"),
+		),
+		DiskUsage: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "export disk usage"),
+		),
+		Range: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "range select"),
+		),
+		RangeUp: key.NewBinding(
+			key.WithKeys("K", "shift+up"),
+			key.WithHelp("K", "extend selection up"),
+		),
+		RangeDown: key.NewBinding(
+			key.WithKeys("J", "shift+down"),
+			key.WithHelp("J", "extend selection down"),
+		),
+		Settings: key.NewBinding(
+			key.WithKeys(","),
+			key.WithHelp(",", "settings"),
+		),
+		Tag: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "edit tags"),
+		),
+		ClearDone: key.NewBinding(
+			// Terminals send ctrl+shift+c as ctrl+c, which quits
+			key.WithKeys("C"),
+			key.WithHelp("C", "clear completed"),
+		),
+		Conflict: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "cycle conflict policy"),
+		),
+		BulkPolicy: key.NewBinding(
+			// Terminals send ctrl+shift+p as ctrl+p, which opens the palette
+			key.WithKeys("P"),
+			key.WithHelp("P", "set policy for many items"),
+		),
+		UnmountStale: key.NewBinding(
+			key.WithKeys("U"),
+			key.WithHelp("U", "unmount stale mounts"),
+		),
+		BwLimit: key.NewBinding(
+			key.WithKeys("b"),
+			key.WithHelp("b", "set bandwidth limit"),
+		),
+		Transfers: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "set parallel transfers"),
+		),
+		Checkers: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "set parallel checkers"),
+		),
+		Clone: key.NewBinding(
+			key.WithKeys("ctrl+d"),
+			key.WithHelp("ctrl+d", "clone remote"),
+		),
+		Import: key.NewBinding(
+			key.WithKeys("I"),
+			key.WithHelp("I", "queue files from a list"),
+		),
+		Diagnose: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "diagnose remote"),
+		),
+		EnableRemote: key.NewBinding(
+			key.WithKeys("E"),
+			key.WithHelp("E", "enable remote"),
+		),
+		MarkRemotes: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "mark remotes"),
+		),
+		DiagnoseMarked: key.NewBinding(
+			key.WithKeys("ctrl+d"),
+			key.WithHelp("ctrl+d", "diagnose marked remotes"),
+		),
+		QuotaSummary: key.NewBinding(
+			key.WithKeys("ctrl+a"),
+			key.WithHelp("ctrl+a", "quotas of marked remotes"),
+		),
+		Pause: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "pause/resume"),
+		),
+		Find: key.NewBinding(
+			key.WithKeys("ctrl+f"),
+			key.WithHelp("ctrl+f", "find by name"),
+		),
+		Mirror: key.NewBinding(
+			key.WithKeys("M"),
+			key.WithHelp("M", "mirror directory"),
+		),
+		NewRemote: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "new remote"),
+		),
+		Recent: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "show recent files"),
+		),
+		DateRange: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "filter by date range"),
+		),
+		FlatList: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "flat listing"),
+		),
+		ShowChanges: key.NewBinding(
+			// Terminals send ctrl+shift+r as ctrl+r
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "show changes"),
+		),
+		ListMode: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "files/dirs/all"),
+		),
 	}
 }
 
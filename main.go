@@ -1,20 +1,135 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"rcloneb/rclone"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
+	cfg := DefaultConfig()
+	flag.BoolVar(&cfg.ShowThumbnails, "thumbnails", cfg.ShowThumbnails, "show image thumbnails in the file browser (kitty graphics terminals)")
+	flag.BoolVar(&cfg.SkipUpdateCheck, "skip-update-check", cfg.SkipUpdateCheck, "don't check for newer rclone releases at startup")
+	flag.BoolVar(&cfg.NoIcons, "no-icons", cfg.NoIcons, "use plain-text labels instead of emoji icons")
+	flag.DurationVar(&cfg.CacheTTL, "cache-ttl", cfg.CacheTTL, "how long directory listings are cached (0 to disable)")
+	flag.StringVar(&cfg.ThemePath, "theme", cfg.ThemePath, "path to a JSON color theme (reloaded on SIGHUP)")
+	flag.StringVar(&cfg.ServeAddr, "serve-addr", cfg.ServeAddr, "listen address when serving a remote")
+	flag.StringVar(&cfg.S3Addr, "s3-addr", cfg.S3Addr, "listen address when serving a remote over S3")
+	flag.StringVar(&cfg.S3AccessKey, "s3-access-key", cfg.S3AccessKey, "access key for the S3 server (random if empty)")
+	flag.StringVar(&cfg.S3SecretKey, "s3-secret-key", cfg.S3SecretKey, "secret key for the S3 server (random if empty)")
+	flag.StringVar(&cfg.FTPAddr, "ftp-addr", cfg.FTPAddr, "listen address when serving a remote over FTP")
+	flag.StringVar(&cfg.FTPUser, "ftp-user", cfg.FTPUser, "user name for the FTP server")
+	flag.StringVar(&cfg.FTPPass, "ftp-pass", cfg.FTPPass, "password for the FTP server (random if empty)")
+	flag.StringVar(&cfg.ResticAddr, "restic-addr", cfg.ResticAddr, "listen address when serving a remote as a restic backend")
+	flag.BoolVar(&cfg.ResticAppendOnly, "restic-append-only", cfg.ResticAppendOnly, "stop restic clients deleting or changing data")
+	flag.BoolVar(&cfg.CompressUploads, "compress-uploads", cfg.CompressUploads, "gzip files written to remotes, adding .gz to their names")
+	flag.BoolVar(&cfg.SkipNewerAtDest, "update", cfg.SkipNewerAtDest, "skip files that are newer locally than on the remote")
+	flag.BoolVar(&cfg.Immutable, "immutable", cfg.Immutable, "fail instead of overwriting local files that differ from the remote")
+	flag.BoolVar(&cfg.NoCheckDest, "no-check-dest", cfg.NoCheckDest, "copy without checking the destination, for write-once storage")
+	flag.BoolVar(&cfg.CreateEmptyDirs, "create-empty-src-dirs", cfg.CreateEmptyDirs, "recreate empty subdirectories when downloading a directory")
+	flag.BoolVar(&cfg.IgnoreExisting, "ignore-existing", cfg.IgnoreExisting, "skip files that already exist locally")
+	flag.BoolVar(&cfg.PreserveMetadata, "metadata", cfg.PreserveMetadata, "copy file metadata such as modification times (rclone 1.59+)")
+	flag.BoolVar(&cfg.UseRC, "rc", cfg.UseRC, "enable rclone's remote control API for live transfer statistics")
+	flag.StringVar(&cfg.RCAddr, "rc-addr", cfg.RCAddr, "address of rclone's remote control API")
+	flag.IntVar(&cfg.BatchSize, "batch-size", cfg.BatchSize, "start downloads automatically when the queue reaches N items")
+	flag.IntVar(&cfg.Retry.MaxAttempts, "retry-attempts", cfg.Retry.MaxAttempts, "run transfers up to this many times on transient errors")
+	flag.DurationVar(&cfg.Retry.BaseDelay, "retry-base-delay", cfg.Retry.BaseDelay, "wait before the first retry, doubled for each later one")
+	flag.DurationVar(&cfg.Retry.MaxDelay, "retry-max-delay", cfg.Retry.MaxDelay, "longest wait between retries")
+	flag.BoolVar(&cfg.ExpandDirs, "expand-dirs", cfg.ExpandDirs, "queue the files inside directories instead of the directories")
+	flag.IntVar(&cfg.MaxQueueDepth, "max-queue-depth", cfg.MaxQueueDepth, "directory levels descended when expanding directories")
+	flag.DurationVar(&cfg.QueueSaveInterval, "queue-save-interval", cfg.QueueSaveInterval, "how long queue changes wait before the queue is saved; raise on slow disks")
+	flag.BoolVar(&cfg.FastListing, "fast-listing", cfg.FastListing, "match files in subdirectories when filtering, using the faster rclone ls")
+	flag.BoolVar(&cfg.FastList, "fast-list", cfg.FastList, "list S3 remotes with rclone's --fast-list, using fewer API calls")
+	flag.BoolVar(&cfg.FetchChecksums, "checksums", cfg.FetchChecksums, "list file hashes with each directory, which is slow on some backends")
+	flag.Int64Var(&cfg.MinSizeFilter, "min-size-filter", cfg.MinSizeFilter, "smallest file size in bytes listed while small files are hidden")
+	flag.StringVar(&cfg.SortOrder, "sort", cfg.SortOrder, "sort listings by name, size, modtime or mimetype, optionally with ,asc or ,desc (sorted by rcloneb, not rclone)")
+	flag.BoolVar(&cfg.DecryptNames, "decrypt-names", cfg.DecryptNames, "show decrypted names when browsing the storage behind a crypt remote")
+	flag.BoolVar(&cfg.Verbose, "verbose", cfg.Verbose, "log diagnostics to the file given by --log-file")
+	flag.StringVar(&cfg.LogPath, "log-file", cfg.LogPath, "where --verbose diagnostics are written")
+	flag.BoolVar(&cfg.DriveStopOnLimit, "drive-stop-on-download-limit", cfg.DriveStopOnLimit, "stop Google Drive downloads at the download limit and retry after a minute")
+	flag.IntVar(&cfg.SFTPConcurrency, "sftp-concurrency", cfg.SFTPConcurrency, "requests in flight per file on SFTP remotes (0 for rclone's default)")
+	flag.BoolVar(&cfg.LowConcurrencyMode, "low-concurrency", cfg.LowConcurrencyMode, "run one transfer and one checker at a time, pausing between files, for remotes that throttle requests")
+	flag.BoolVar(&cfg.WriteChecksumFile, "write-md5", cfg.WriteChecksumFile, "write an MD5 file beside every downloaded file")
+	flag.Func("cutoff-mode", "how transfers stop at rclone's --max-transfer limit: hard, soft or cautious", func(s string) error {
+		if !slices.Contains(rclone.CutoffModes, s) {
+			return fmt.Errorf("want one of %s", strings.Join(rclone.CutoffModes, ", "))
+		}
+		cfg.CutoffMode = s
+		return nil
+	})
+	flag.Func("exclude", "pattern to skip (and delete locally) when mirroring; may be repeated", func(s string) error {
+		cfg.Excludes = append(cfg.Excludes, s)
+		return nil
+	})
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [remote:path]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if cfg.SortOrder != "" {
+		if _, err := rclone.SortLess(cfg.SortOrder); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --sort: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	if flag.NArg() > 0 {
+		cfg.StartPath = flag.Arg(0)
+	}
+
+	if cfg.Verbose {
+		f, err := tea.LogToFile(cfg.LogPath, "rcloneb")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening log file: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+	}
+
+	// A missing theme file simply keeps the built-in colors
+	if theme, err := LoadTheme(cfg.ThemePath); err == nil {
+		applyTheme(theme)
+	}
+
 	p := tea.NewProgram(
-		NewModel(),
+		NewModel(cfg),
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
 	)
 
-	if _, err := p.Run(); err != nil {
+	// Reload the theme whenever SIGHUP is received
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			p.Send(themeReloadMsg{})
+		}
+	}()
+
+	final, err := p.Run()
+	var orphans []serverRecord
+	if fm, ok := final.(Model); ok {
+		orphans = fm.orphanServers
+		fm.queue.Close()
+		fm.removeThumbnail()
+		if total := fm.sessionTransferred(); total > 0 {
+			_ = appendHistory("session transferred " + rclone.FormatSize(total))
+		}
+	}
+
+	// Servers the user left running must not outlive us
+	rclone.StopServers(5 * time.Second)
+	_ = saveServers(nil, orphans)
+
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,53 @@
+package main
+
+import "strings"
+
+const (
+	// minimapMinWidth is the terminal width above which the browser shows
+	// the minimap
+	minimapMinWidth = 120
+
+	// minimapWidth is the width of the minimap column
+	minimapWidth = 24
+
+	// minimapAncestorWidth is how much of each ancestor's name is shown
+	minimapAncestorWidth = 12
+)
+
+// minimapEnabled reports whether the browser has room for the minimap
+func (m Model) minimapEnabled() bool {
+	return m.width > minimapMinWidth
+}
+
+// minimapLines draws the path of the current directory as an indented tree,
+// one level per line, in at most rows lines. Deep paths keep their deepest
+// levels, as those are the ones the user is moving between.
+func (m Model) minimapLines(rows int) []string {
+	levels := []string{m.currentRemote + ":"}
+	if m.currentPath != "" {
+		levels = append(levels, strings.Split(m.currentPath, "/")...)
+	}
+
+	first := max(len(levels)-rows, 0)
+	lines := make([]string, 0, len(levels)-first)
+	for depth := first; depth < len(levels); depth++ {
+		// Indent one column per level, leaving room for a few characters
+		indent := strings.Repeat(" ", min(depth, minimapWidth-minimapAncestorWidth))
+		if depth == len(levels)-1 {
+			lines = append(lines, indent+cursorStyle.Render(truncateName(levels[depth], minimapWidth-len(indent))))
+		} else {
+			lines = append(lines, indent+helpStyle.Render(truncateName(levels[depth], minimapAncestorWidth)))
+		}
+	}
+	return lines
+}
+
+// truncateName shortens name to at most width characters, ending it with "…"
+// when cut
+func truncateName(name string, width int) string {
+	runes := []rune(name)
+	if len(runes) <= width || width < 1 {
+		return name
+	}
+	return string(runes[:width-1]) + "…"
+}
@@ -2,11 +2,23 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"rcloneb/queue"
 	"rcloneb/rclone"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -21,12 +33,35 @@ const (
 	StateFileBrowser
 	StateQueueView
 	StateTransferView
+	StateFileViewer
+	StateQueuePreview
+	StateCommandPalette
+	StateSettings
+	StateMirrorConfirm
+	StateNewRemote
+	StateConfigWizard
+	StateDestinationInput
+	StateResumePrompt
+	StatePasteFile
+	StatePurgeConfirm
+	StateImportFilelist
+	StateCloneRemote
+	StateAuthorizing
+	StateDiagnostics
+	StateQuotaSummary
 )
 
+// recentWindow is how far back the "show recent" browser toggle looks
+const recentWindow = 24 * time.Hour
+
+// viewerPageSize is the number of bytes fetched per file viewer page
+const viewerPageSize = 4 * 1024
+
 // BrowserItem extends FileItem with selection state
 type BrowserItem struct {
 	rclone.FileItem
 	Selected bool
+	Deep     bool // Found by a recursive name-only listing; only shown while filtering
 }
 
 // Model represents the main application state
@@ -37,6 +72,52 @@ type Model struct {
 	// Remotes
 	remotes       []string
 	selectedIndex int
+	cryptRemotes  map[string]bool   // Remotes using the crypt backend
+	decrypted     map[string]string // Decrypted names of the listed entries, keyed by stored name
+	remoteTypes   map[string]string // Backend type by remote name, empty if unknown
+
+	// Storage quotas of the remotes that report a total
+	quotas map[string]rclone.QuotaInfo
+
+	// Remotes turned off with "disable" in the rclone config
+	disabledRemotes map[string]bool
+
+	// The last listing could not reach its remote, so waiting for it is
+	// offered; waitCancel stops a wait in progress
+	waitOffered bool
+	waitCancel  context.CancelFunc
+
+	// Cursor positions of directories left for a subdirectory, keyed by
+	// remote:path and restored when their listing next loads
+	cursorHistory map[string]int
+
+	// Connectivity test results for the remotes being diagnosed, by remote
+	diagRemotes []string
+	diagResults map[string][]rclone.DiagResult
+
+	// Remotes marked in the remote list's multi-select mode, for running
+	// diagnostics or reading quotas on all of them at once
+	remoteMarking bool
+	markedRemotes map[string]bool
+
+	// Quotas read for the quota summary, by remote
+	summaryRemotes []string
+	summaryQuotas  map[string]rclone.QuotaInfo
+	summaryErrs    map[string]error
+
+	// The rclone config is encrypted, so reading it needs RCLONE_CONFIG_PASS
+	configEncrypted bool
+
+	// The help for the current view is shown over it
+	showHelp bool
+
+	// The warning of the selected setting waits for confirmation
+	settingsWarning bool
+
+	// The download directory may be too full for the batch; while
+	// spacePending the batch waits for the user to start it anyway
+	spaceWarning string
+	spacePending bool
 
 	// File browser
 	currentRemote string
@@ -44,20 +125,147 @@ type Model struct {
 	pathStack     []string // For back navigation
 	files         []BrowserItem
 	fileIndex     int
+	rangeMode     bool            // Moving the cursor extends the selection
+	rangeStart    int             // Index where the range selection started
+	rangeBase     map[string]bool // Paths already selected when the range started
+	listCache     *rclone.ListCache
+	showRecent    bool      // Only list files modified within recentWindow
+	cachedAt      time.Time // When the shown listing was cached; zero if listed fresh
+	hideSmall     bool      // Only list files of at least Config.MinSizeFilter
+	flatList      bool      // List every file below the current path, without directories
+	listMode      rclone.ListMode
+	deepLoaded    bool // files includes the recursive listing used by the filter
+
+	// The last full listing of each remote:path, and the files changed since
+	// the one before it, keyed by path, for changesKey
+	listings   map[string][]rclone.FileItem
+	changes    map[string]string
+	changesKey string
+
+	// Only list files modified in this range; zero ends are open
+	dateFrom   time.Time
+	dateTo     time.Time
+	dateMode   bool // Editing the date range
+	dateInputs []textinput.Model
+	dateFocus  int
+
+	// File detail panel
+	showDetail bool
+	hashCache  map[string]string // Keyed by remote:path:algo
+	hashing    bool
+
+	// File viewer
+	viewerPath    string
+	viewerData    []byte // Pages fetched so far
+	viewerScroll  int
+	viewerEOF     bool
+	viewerLoading bool
+	viewerLocal   bool     // viewerPath is a local file rather than on currentRemote
+	viewerReturn  AppState // State to return to when the viewer closes
 
 	// Filtering
 	filterMode  bool
 	filterInput textinput.Model
 	filterText  string
 
+	// Command palette
+	paletteInput  textinput.Model
+	paletteIndex  int
+	paletteReturn AppState // State to return to when the palette closes
+	paletteRecent []string // Command names, most recently used first
+
 	// Download queue
 	queue *queue.Queue
+	batch *queue.Queue // Items being previewed or transferred, a subset of queue
+
+	// batchTransfers are the batch's items as started, with their transfer IDs
+	batchTransfers []batchTransfer
+
+	// Queue search
+	queueSearchMode  bool
+	queueSearchInput textinput.Model
+
+	// Tag editor for the highlighted queue item
+	tagMode  bool
+	tagInput textinput.Model
+
+	// Menu setting one conflict policy on many queue items; policyGlobMode
+	// is set while the glob for applying it by extension is typed
+	policyMenu     bool
+	bulkPolicy     queue.ConflictPolicy
+	policyGlobMode bool
+	policyGlob     textinput.Model
+
+	// Destination directory prompt
+	destInput textinput.Model
+
+	// Local file list path prompt for bulk queueing
+	importInput textinput.Model
+
+	// Dry-run preview of the queue
+	previewFiles   []string
+	previewLoading bool
 
 	// Transfer management
 	transferMgr    *rclone.TransferManager
+	sessionBytes   int64 // Bytes copied by batches finished this session
 	transferCtx    context.Context
 	transferCancel context.CancelFunc
+	transferOpts   rclone.CopyOptions // Options the running batch was started with
 	progressBar    progress.Model
+	autoStarted    bool                // Downloads were started by the batch size trigger
+	globalStats    *rclone.GlobalStats // Latest RC stats, nil when not polling
+	peakSpeed      int64
+
+	// Flag of the running transfer being changed over the RC API; empty when not prompting
+	rcFlag      string
+	rcFlagInput textinput.Model
+
+	// Three-column transfer view: scroll offsets and the column keys act on
+	colScrollPending int
+	colScrollDone    int
+	transferColumn   int
+
+	// Transfers left unfinished by a previous run, offered for resuming
+	interrupted  []progressRecord
+	resumeReturn AppState
+
+	// One-time informational banner shown in the file browser
+	banner      string
+	bannerShown map[string]bool
+
+	// Running "rclone serve" subprocesses keyed by protocol
+	servers map[string]activeServer
+
+	// Serve processes a previous run left running
+	orphanServers []serverRecord
+
+	// rclone mounts whose rclone process has died
+	staleMounts []rclone.MountInfo
+
+	// Unmounting the stale mounts waits for a y/N
+	unmountConfirm bool
+
+	// Newer rclone release found at startup; empty if none
+	rcloneUpdate string
+
+	// Zip archive being extracted locally, nil when idle
+	extract *extractState
+
+	// Internal clipboard holding a remote path copied in the file browser
+	clipboard string
+
+	// File name prompt for pasting the clipboard into a new remote file
+	pasteInput textinput.Model
+
+	// Directory awaiting purge confirmation
+	purgeTarget rclone.FileItem
+	purgeInput  textinput.Model
+	purgeTyped  bool // The name was typed correctly; waiting for the final y/N
+
+	// Short-lived status message
+	flash   string
+	flashID int
 
 	// UI state
 	width   int
@@ -66,16 +274,75 @@ type Model struct {
 	spinner spinner.Model
 	err     error
 
+	// Image thumbnails
+	graphics  graphicsProtocol
+	thumbPath string // Path the current thumbnail belongs to
+	thumbnail string // PNG file of the current thumbnail, read by the terminal
+
+	// New remote form and detected credentials
+	newRemoteInputs []textinput.Model // Name, type and options
+	newRemoteFocus  int
+	creatingRemote  bool
+	detected        []detectedCredential
+	backends        []rclone.BackendInfo // Loaded once per session for the type selector
+	cloneInput      textinput.Model      // Name for a copy of the highlighted remote
+	backendIndex    int
+	detectedIndex   int
+
+	// Browser authorization of an OAuth remote
+	oauthRemote string
+	oauthURL    string
+	oauthCancel context.CancelFunc
+
+	// Settings
+	config         Config
+	settingsIndex  int
+	settingsReturn AppState
+
 	// Keybindings
 	keys KeyMap
 }
 
 // NewModel creates a new application model
-func NewModel() Model {
+func NewModel(cfg Config) Model {
 	ti := textinput.New()
 	ti.Placeholder = "Type to filter..."
 	ti.Prompt = "/ "
 
+	pi := textinput.New()
+	pi.Placeholder = "Type a command..."
+	pi.Prompt = "> "
+
+	qi := textinput.New()
+	qi.Placeholder = "File name..."
+	qi.Prompt = "find: "
+
+	fi := textinput.New()
+	fi.Placeholder = "notes.txt"
+	fi.Prompt = "file name: "
+
+	tgi := textinput.New()
+	tgi.Placeholder = "work, photos"
+	tgi.Prompt = "tags: "
+
+	gli := textinput.New()
+	gli.Placeholder = "*.mkv"
+	gli.Prompt = "glob: "
+
+	pgi := textinput.New()
+	pgi.Prompt = "> "
+
+	ci := textinput.New()
+	ci.Prompt = "new name: "
+
+	ii := textinput.New()
+	ii.Placeholder = "/path/to/filelist.txt"
+	ii.Prompt = "file list: "
+
+	di := textinput.New()
+	di.Placeholder = "/path/to/downloads"
+	di.Prompt = "destination: "
+
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = spinnerStyle
@@ -85,39 +352,157 @@ func NewModel() Model {
 		progress.WithWidth(40),
 	)
 
-	return Model{
-		state:         StateRemoteSelect,
-		queue:         queue.New(),
-		filterInput:   ti,
-		spinner:       s,
-		progressBar:   prog,
-		keys:          DefaultKeyMap(),
-		selectedIndex: 0,
+	m := Model{
+		state:            StateRemoteSelect,
+		queue:            queue.New(),
+		filterInput:      ti,
+		paletteInput:     pi,
+		queueSearchInput: qi,
+		tagInput:         tgi,
+		policyGlob:       gli,
+		destInput:        di,
+		importInput:      ii,
+		dateInputs:       newDateInputs(),
+		rcFlagInput:      textinput.New(),
+		cloneInput:       ci,
+		pasteInput:       fi,
+		purgeInput:       pgi,
+		spinner:          s,
+		progressBar:      prog,
+		keys:             DefaultKeyMap(),
+		selectedIndex:    0,
+		config:           cfg,
+		graphics:         detectGraphicsProtocol(),
+		cryptRemotes:     make(map[string]bool),
+		bannerShown:      make(map[string]bool),
+		hashCache:        make(map[string]string),
+		listCache:        rclone.NewListCache(),
+		listings:         make(map[string][]rclone.FileItem),
+		quotas:           make(map[string]rclone.QuotaInfo),
+		servers:          make(map[string]activeServer),
+		cursorHistory:    make(map[string]int),
+		markedRemotes:    make(map[string]bool),
 	}
+
+	persistQueue(m.queue, cfg.QueueSaveInterval, cfg.Verbose)
+
+	// Open a remote directly when one was given on the command line
+	if remote, path, ok := strings.Cut(cfg.StartPath, ":"); ok && remote != "" {
+		m.state = StateFileBrowser
+		m.currentRemote = remote
+		m.currentPath = strings.Trim(path, "/")
+		m.loading = true
+	}
+	return m
 }
 
 // Init initializes the model
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(
+	cmds := []tea.Cmd{
 		m.loadRemotes(),
 		m.spinner.Tick,
-	)
+		checkInterrupted(),
+		checkOrphanedServers(),
+		checkStaleMounts(),
+	}
+	if !m.config.SkipUpdateCheck {
+		cmds = append(cmds, checkRcloneUpdate())
+	}
+	if m.state == StateFileBrowser {
+		cmds = append(cmds, m.loadFiles())
+	}
+	return tea.Batch(cmds...)
 }
 
 // Messages for async operations
 
+// rcloneUpdateMsg is sent when a newer rclone release is available
+type rcloneUpdateMsg struct {
+	version string
+}
+
+// checkRcloneUpdate returns a command that looks for a newer rclone release.
+// Failures, such as being offline, are ignored.
+func checkRcloneUpdate() tea.Cmd {
+	return func() tea.Msg {
+		available, version, err := rclone.CheckForUpdate()
+		if err != nil || !available {
+			return nil
+		}
+		return rcloneUpdateMsg{version: version}
+	}
+}
+
 // remotesLoadedMsg is sent when remotes are loaded
 type remotesLoadedMsg struct {
-	remotes []string
-	err     error
+	remotes   []string
+	types     map[string]string
+	crypt     []string
+	disabled  []string
+	encrypted bool // The rclone config is encrypted
+	err       error
+}
+
+// cryptInfoMsg is sent when the underlying path of a crypt remote is known
+type cryptInfoMsg struct {
+	remote     string
+	underlying string
 }
 
 // filesLoadedMsg is sent when files are loaded
 type filesLoadedMsg struct {
-	files []rclone.FileItem
+	files    []rclone.FileItem
+	cachedAt time.Time // When a listing served from cache was stored
+	err      error
+}
+
+// checksumMsg is sent when a file hash has been computed
+type checksumMsg struct {
+	key  string
+	hash string
+	err  error
+}
+
+// viewerPageMsg is sent when a page of the viewed file has been fetched
+type viewerPageMsg struct {
+	path   string
+	offset int64
+	data   []byte
+	err    error
+}
+
+// previewLoadedMsg is sent when the dry-run preview of the queue is ready
+type previewLoadedMsg struct {
+	files []string
+	sizes map[string]int64 // Sizes found for files queued with an unknown size, keyed by remote:path
 	err   error
 }
 
+// globalStatsMsg carries a stats snapshot from the RC poller
+type globalStatsMsg struct {
+	stats rclone.GlobalStats
+	ch    <-chan rclone.GlobalStats
+}
+
+// waitForStats returns a command that waits for the next RC stats snapshot
+func waitForStats(ch <-chan rclone.GlobalStats) tea.Cmd {
+	return func() tea.Msg {
+		stats, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return globalStatsMsg{stats: stats, ch: ch}
+	}
+}
+
+// themeReloadMsg asks the model to reload the theme file from disk
+type themeReloadMsg struct{}
+
+// clearFlashMsg clears the flash message if it has not been replaced since
+type clearFlashMsg struct {
+	id int
+}
+
 // tickMsg is sent periodically to update the transfer UI
 type tickMsg time.Time
 
@@ -128,30 +513,546 @@ func tickCmd() tea.Cmd {
 	})
 }
 
+// fileWrittenMsg is sent when clipboard text has been written to a remote file
+type fileWrittenMsg struct {
+	path  string
+	ratio float64 // Estimated compressed size as a fraction of the original; zero if uncompressed
+	err   error
+}
+
+// pasteToFile returns a command that writes the system clipboard, or the
+// internal clipboard if the system one is unavailable, to a file in the current directory
+func (m Model) pasteToFile(name string) tea.Cmd {
+	remote := m.currentRemote
+	filePath := name
+	if m.currentPath != "" {
+		filePath = m.currentPath + "/" + name
+	}
+	fallback := m.clipboard
+	compress := m.config.CompressUploads
+	if compress {
+		filePath += ".gz"
+	}
+
+	return func() tea.Msg {
+		text, err := clipboard.ReadAll()
+		if err != nil || text == "" {
+			text = fallback
+		}
+		if text == "" {
+			return fileWrittenMsg{path: filePath, err: fmt.Errorf("clipboard is empty")}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if compress {
+			ratio, err := rclone.EstimateGzipRatio(strings.NewReader(text))
+			if err == nil {
+				err = rclone.CopyFromStdinGzipped(ctx, strings.NewReader(text), remote, filePath)
+			}
+			return fileWrittenMsg{path: filePath, ratio: ratio, err: err}
+		}
+		err = rclone.CopyFromStdin(ctx, strings.NewReader(text), remote, filePath)
+		return fileWrittenMsg{path: filePath, err: err}
+	}
+}
+
+// pasteInto inserts text at the cursor of a text input
+func pasteInto(in *textinput.Model, text string) {
+	value := []rune(in.Value())
+	pos := in.Position()
+	if pos > len(value) {
+		pos = len(value)
+	}
+	in.SetValue(string(value[:pos]) + text + string(value[pos:]))
+	in.SetCursor(pos + len([]rune(text)))
+}
+
+// showFlash displays a status message for the given duration
+func (m *Model) showFlash(text string, d time.Duration) tea.Cmd {
+	m.flash = text
+	m.flashID++
+	id := m.flashID
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return clearFlashMsg{id: id}
+	})
+}
+
 // loadRemotes returns a command to load remotes
 func (m Model) loadRemotes() tea.Cmd {
 	return func() tea.Msg {
-		remotes, err := rclone.ListRemotes()
-		return remotesLoadedMsg{remotes: remotes, err: err}
+		encrypted, _ := rclone.ConfigEncrypted()
+
+		// Grouping needs --long, which older rclone versions lack
+		groups, err := rclone.ListRemotesGrouped()
+		if err != nil {
+			remotes, err := rclone.ListRemotes()
+			return remotesLoadedMsg{remotes: remotes, encrypted: encrypted, err: err}
+		}
+
+		// listremotes may leave out disabled remotes, so they come from the config
+		var disabled []string
+		if all, err := rclone.ListRemotesAll(); err == nil {
+			for _, r := range all {
+				if !r.Disabled {
+					continue
+				}
+				disabled = append(disabled, r.Name)
+				if !slices.Contains(groups[r.Type], r.Name) {
+					groups[r.Type] = append(groups[r.Type], r.Name)
+				}
+			}
+		}
+
+		// Order remotes by section so the cursor moves through them as displayed
+		var remotes []string
+		types := make(map[string]string)
+		for _, typ := range sortedProviderTypes(groups) {
+			names := groups[typ]
+			sort.Strings(names)
+			for _, name := range names {
+				remotes = append(remotes, name)
+				types[name] = typ
+			}
+		}
+		return remotesLoadedMsg{remotes: remotes, types: types, crypt: groups["crypt"], disabled: disabled, encrypted: encrypted}
+	}
+}
+
+// remoteReachableMsg is sent when waiting for a remote ends
+type remoteReachableMsg struct {
+	remote string
+	err    error
+}
+
+// waitForRemoteInterval is how often an unreachable remote is tried again
+const waitForRemoteInterval = 5 * time.Second
+
+// waitForRemote returns a command that waits until remote can be reached
+// again, or ctx is cancelled
+func waitForRemote(ctx context.Context, remote string) tea.Cmd {
+	return func() tea.Msg {
+		return remoteReachableMsg{remote: remote, err: rclone.WaitForRemote(ctx, remote, waitForRemoteInterval)}
+	}
+}
+
+// enabledMsg is sent when a disabled remote has been enabled
+type enabledMsg struct {
+	remote string
+	err    error
+}
+
+// enableRemote returns a command that clears the disable setting of remote
+func enableRemote(remote string) tea.Cmd {
+	return func() tea.Msg {
+		return enabledMsg{remote: remote, err: rclone.EnableRemote(remote)}
+	}
+}
+
+// quotaLoadedMsg is sent when a remote's storage quota has been read
+type quotaLoadedMsg struct {
+	info rclone.QuotaInfo
+	err  error
+}
+
+// loadQuotas returns a command that reads the quota of every remote
+func loadQuotas(remotes []string) tea.Cmd {
+	cmds := make([]tea.Cmd, 0, len(remotes))
+	for _, remote := range remotes {
+		remote := remote
+		cmds = append(cmds, func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			info, err := rclone.About(ctx, remote)
+			return quotaLoadedMsg{info: info, err: err}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// markedRemoteList returns the remotes marked in multi-select mode, in list order
+func (m Model) markedRemoteList() []string {
+	var marked []string
+	for _, remote := range m.remotes {
+		if m.markedRemotes[remote] {
+			marked = append(marked, remote)
+		}
+	}
+	return marked
+}
+
+// namesDecryptedMsg is sent when the names in a directory have been decrypted
+type namesDecryptedMsg struct {
+	remote string
+	path   string
+	names  map[string]string
+	err    error
+	quiet  bool // Don't report a failure; the listing was merely refreshed
+}
+
+// decryptNames returns a command that decrypts the names of the listed entries
+// using the crypt remote wrapping the current path
+func (m Model) decryptNames(quiet bool) tea.Cmd {
+	remote := m.currentRemote
+	dir := m.currentPath
+
+	// Deep listings hold relative paths; every segment is encrypted separately
+	seen := make(map[string]bool)
+	var names []string
+	for _, f := range m.files {
+		for _, seg := range strings.Split(f.Name, "/") {
+			if !seen[seg] {
+				seen[seg] = true
+				names = append(names, seg)
+			}
+		}
+	}
+
+	return func() tea.Msg {
+		msg := namesDecryptedMsg{remote: remote, path: dir, quiet: quiet}
+		crypt, err := rclone.CryptRemoteFor(remote, dir)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		msg.names, msg.err = rclone.DecryptNames(crypt, names)
+		return msg
 	}
 }
 
-// loadFiles returns a command to load files at the current path
+// displayName returns a listed name, decrypted segment by segment when names
+// are being decrypted
+func (m Model) displayName(name string) string {
+	if !m.config.DecryptNames || len(m.decrypted) == 0 {
+		return name
+	}
+	segs := strings.Split(name, "/")
+	for i, seg := range segs {
+		if dec, ok := m.decrypted[seg]; ok {
+			segs[i] = dec
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+// loadCryptInfo returns a command to look up the path a crypt remote wraps
+func loadCryptInfo(remote string) tea.Cmd {
+	return func() tea.Msg {
+		underlying, err := rclone.GetCryptRemoteUnderlying(remote)
+		if err != nil {
+			return nil
+		}
+		return cryptInfoMsg{remote: remote, underlying: underlying}
+	}
+}
+
+// loadFiles returns a command to load files at the current path,
+// serving the listing from cache when possible
 func (m Model) loadFiles() tea.Cmd {
+	return m.listFiles(false)
+}
+
+// reloadFiles returns a command to re-list the current path, bypassing the cache
+func (m Model) reloadFiles() tea.Cmd {
+	return m.listFiles(true)
+}
+
+// listFiles returns a command that lists the current path, bypassing and
+// repopulating the cache when forceRefresh is set
+func (m Model) listFiles(forceRefresh bool) tea.Cmd {
 	remote := m.currentRemote
 	path := m.currentPath
+	cache := m.listCache
+	ttl := m.config.CacheTTL
+	verbose := m.config.Verbose
+	opts := rclone.ListOptions{SortOrder: m.config.SortOrder, Mode: m.listMode, FetchChecksums: m.config.FetchChecksums, FastList: m.config.FastList}
+	if m.showRecent {
+		since := time.Now().Add(-recentWindow)
+		return func() tea.Msg {
+			files, err := rclone.ListFilesModifiedSince(remote, path, since)
+			if err == nil {
+				err = rclone.SortFiles(files, opts.SortOrder)
+			}
+			return filesLoadedMsg{files: files, err: err}
+		}
+	}
+	if m.dateFiltered() {
+		// The to date is inclusive, so the range ends at the following midnight
+		from, to := m.dateFrom, m.dateTo
+		if !to.IsZero() {
+			to = to.AddDate(0, 0, 1)
+		}
+		return func() tea.Msg {
+			files, err := rclone.ListFilesBetween(context.Background(), remote, path, from, to)
+			if err == nil {
+				err = rclone.SortFiles(files, opts.SortOrder)
+			}
+			return filesLoadedMsg{files: files, err: err}
+		}
+	}
+	if m.flatList {
+		return func() tea.Msg {
+			files, err := rclone.ListFilesFlat(context.Background(), remote, path)
+			if err == nil {
+				err = rclone.SortFiles(files, opts.SortOrder)
+			}
+			return filesLoadedMsg{files: files, err: err}
+		}
+	}
+	if m.hideSmall {
+		minSize := m.config.MinSizeFilter
+		return func() tea.Msg {
+			files, err := rclone.ListFilesWithMinSize(remote, path, minSize)
+			if err == nil {
+				err = rclone.SortFiles(files, opts.SortOrder)
+			}
+			return filesLoadedMsg{files: files, err: err}
+		}
+	}
+	if opts.Mode != rclone.ListAll {
+		// The cache holds full listings only
+		return func() tea.Msg {
+			files, parseErrs, err := rclone.ListFilesWithOptions(remote, path, opts)
+			if verbose {
+				for _, e := range parseErrs {
+					log.Printf("listing %s:%s: %v", remote, path, e)
+				}
+			}
+			return filesLoadedMsg{files: files, err: err}
+		}
+	}
+	return func() tea.Msg {
+		var cachedAt time.Time
+		if age, ok := cache.Age(remote, path); ok && !forceRefresh {
+			cachedAt = time.Now().Add(-age)
+		}
+		files, parseErrs, err := cache.ListFilesCached(remote, path, opts, forceRefresh, ttl)
+
+		// Unreadable entries are dropped from the listing, never fatal
+		if verbose {
+			for _, e := range parseErrs {
+				log.Printf("listing %s:%s: %v", remote, path, e)
+			}
+		}
+		if err == nil {
+			// Listings are cached in rclone's order
+			err = rclone.SortFiles(files, opts.SortOrder)
+		}
+		return filesLoadedMsg{files: files, cachedAt: cachedAt, err: err}
+	}
+}
+
+// sortOrders are the listing orders the sort key cycles through; empty is rclone's name order
+var sortOrders = []string{"", "name,desc", "size,desc", "size,asc", "modtime,desc", "modtime,asc", "mimetype"}
+
+// nextSortOrder returns the order after the current one in sortOrders
+func (m Model) nextSortOrder() string {
+	for i, o := range sortOrders {
+		if o == m.config.SortOrder {
+			return sortOrders[(i+1)%len(sortOrders)]
+		}
+	}
+	return sortOrders[0]
+}
+
+// hashAlgorithms lists the checksum algorithms offered in the detail panel
+var hashAlgorithms = []string{"md5", "sha1", "sha256"}
+
+// hashKey returns the checksum cache key for a remote file and algorithm
+func hashKey(remote, path, algo string) string {
+	return remote + ":" + path + ":" + algo
+}
+
+// computeChecksum returns a command that hashes the next uncached algorithm
+// for the file under the cursor, or nil if every algorithm is cached
+func (m *Model) computeChecksum() tea.Cmd {
+	files := m.filteredFiles()
+	if m.hashing || m.fileIndex < 0 || m.fileIndex >= len(files) || files[m.fileIndex].IsDir {
+		return nil
+	}
+	f := files[m.fileIndex]
+
+	for _, algo := range hashAlgorithms {
+		key := hashKey(m.currentRemote, f.Path, algo)
+		if _, ok := m.hashCache[key]; ok {
+			continue
+		}
+		if hash := f.Hashes[algo]; hash != "" {
+			// Listed with checksums, so no need to ask the remote
+			m.hashCache[key] = hash
+			continue
+		}
+		m.hashing = true
+		remote := m.currentRemote
+		return func() tea.Msg {
+			hash, err := fileHash(context.Background(), remote, f.Path, algo)
+			return checksumMsg{key: key, hash: hash, err: err}
+		}
+	}
+	return nil
+}
+
+// fileHash returns the hash of a single remote file
+func fileHash(ctx context.Context, remote, filePath, algo string) (string, error) {
+	var sums map[string]string
+	var err error
+	switch algo {
+	case "md5":
+		sums, err = rclone.MD5Sum(ctx, remote, filePath)
+	case "sha1":
+		sums, err = rclone.SHA1Sum(ctx, remote, filePath)
+	default:
+		return rclone.ChecksumFile(ctx, remote, filePath, algo)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// The only entry is keyed by the file's base name
+	for _, hash := range sums {
+		return hash, nil
+	}
+	return "", fmt.Errorf("no %s hash returned for %s:%s", algo, remote, filePath)
+}
+
+// loadPreview returns a command that dry-runs every queue item
+func (m Model) loadPreview() tea.Cmd {
+	items := m.batch.Items()
+	cwd := downloadDir()
 	return func() tea.Msg {
-		files, err := rclone.ListFiles(remote, path)
-		return filesLoadedMsg{files: files, err: err}
+		var files []string
+		sizes := make(map[string]int64)
+		for _, item := range items {
+			dest := itemDest(item, cwd)
+			if item.IsDir {
+				// Directories are copied into a directory of the same name, as CopyDir does
+				dest = filepath.Join(dest, path.Base(item.Path))
+			}
+			names, err := rclone.DryRunCopy(context.Background(), item.Remote, item.Path, dest)
+			if err != nil {
+				return previewLoadedMsg{err: err}
+			}
+			files = append(files, names...)
+
+			// Listings can report no size, which would understate the total
+			if !item.IsDir && item.Size <= 0 {
+				if exists, size, err := rclone.FileStat(context.Background(), item.Remote, item.Path); err == nil && exists && size > 0 {
+					sizes[item.Remote+":"+item.Path] = size
+				}
+			}
+		}
+		return previewLoadedMsg{files: files, sizes: sizes}
+	}
+}
+
+// openViewer switches to the file viewer and fetches the first page
+func (m *Model) openViewer(path string) tea.Cmd {
+	m.viewerReturn = m.state
+	m.state = StateFileViewer
+	m.viewerPath = path
+	m.viewerLocal = false
+	m.viewerData = nil
+	m.viewerScroll = 0
+	m.viewerEOF = false
+	return m.loadViewerPage()
+}
+
+// openLocalViewer opens a local file in the file viewer
+func (m *Model) openLocalViewer(path string) tea.Cmd {
+	cmd := m.openViewer(path)
+	m.viewerLocal = true
+	return cmd
+}
+
+// loadViewerPage returns a command that fetches the next page of the viewed file
+func (m *Model) loadViewerPage() tea.Cmd {
+	if m.viewerLoading || m.viewerEOF {
+		return nil
+	}
+	m.viewerLoading = true
+	remote := m.currentRemote
+	path := m.viewerPath
+	local := m.viewerLocal
+	offset := int64(len(m.viewerData))
+	return func() tea.Msg {
+		if local {
+			data, err := readLocalPage(path, offset, viewerPageSize)
+			return viewerPageMsg{path: path, offset: offset, data: data, err: err}
+		}
+		data, err := rclone.Cat(context.Background(), remote, path, offset, viewerPageSize)
+		return viewerPageMsg{path: path, offset: offset, data: data, err: err}
+	}
+}
+
+// ncduExportedMsg is sent when a disk usage export has been written
+type ncduExportedMsg struct {
+	file string
+	err  error
+}
+
+// ncduClosedMsg is sent when the external ncdu viewer exits
+type ncduClosedMsg struct {
+	err error
+}
+
+// exportDiskUsage returns a command that writes an ncdu export of a whole remote
+func (m *Model) exportDiskUsage(remote string) tea.Cmd {
+	file := filepath.Join(os.TempDir(), "rcloneb-"+remote+"-ncdu.json")
+	flash := m.showFlash("Exporting disk usage of "+remote+"...", time.Minute)
+	return tea.Batch(flash, func() tea.Msg {
+		err := rclone.NcduExport(context.Background(), remote, "", file)
+		return ncduExportedMsg{file: file, err: err}
+	})
+}
+
+// openDiskUsage views an export with ncdu, or as raw JSON if ncdu is not installed
+func (m *Model) openDiskUsage(file string) tea.Cmd {
+	if ncdu, err := exec.LookPath("ncdu"); err == nil {
+		return tea.ExecProcess(exec.Command(ncdu, "-f", file), func(err error) tea.Msg {
+			return ncduClosedMsg{err: err}
+		})
 	}
+	return m.openLocalViewer(file)
+}
+
+// readLocalPage reads up to count bytes at offset from a local file
+func readLocalPage(path string, offset, count int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, count)
+	n, err := f.ReadAt(buf, offset)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// viewerLines splits the fetched file content into display lines
+func (m Model) viewerLines() []string {
+	text := strings.ToValidUTF8(string(m.viewerData), "?")
+	return strings.Split(strings.ReplaceAll(text, "\t", "    "), "\n")
 }
 
 // filteredFiles returns files matching the current filter
 func (m Model) filteredFiles() []BrowserItem {
 	if m.filterText == "" {
-		return m.files
+		if !m.deepLoaded {
+			return m.files
+		}
+		var shallow []BrowserItem
+		for _, f := range m.files {
+			if !f.Deep {
+				shallow = append(shallow, f)
+			}
+		}
+		return shallow
 	}
 
+	// With fast listing on, files in subdirectories match too
 	var filtered []BrowserItem
 	for _, f := range m.files {
 		if containsIgnoreCase(f.Name, m.filterText) {
@@ -161,6 +1062,43 @@ func (m Model) filteredFiles() []BrowserItem {
 	return filtered
 }
 
+// deepListingMsg is sent when a recursive name-only listing has finished
+type deepListingMsg struct {
+	remote string
+	path   string
+	files  []rclone.FileItem
+	err    error
+}
+
+// loadDeepListing returns a command that lists every file below the current path
+// by name, or nil if fast listing is off or the listing is already loaded
+func (m Model) loadDeepListing() tea.Cmd {
+	if !m.config.FastListing || m.deepLoaded {
+		return nil
+	}
+	remote, path := m.currentRemote, m.currentPath
+	return func() tea.Msg {
+		files, err := rclone.LsFast(remote, path)
+		return deepListingMsg{remote: remote, path: path, files: files, err: err}
+	}
+}
+
+// mergeDeepListing adds files from subdirectories to the current listing
+func (m *Model) mergeDeepListing(files []rclone.FileItem) {
+	for _, f := range files {
+		// Files directly in this directory are already listed
+		if !strings.Contains(f.Name, "/") {
+			continue
+		}
+		f.Path = f.Name
+		if m.currentPath != "" {
+			f.Path = m.currentPath + "/" + f.Name
+		}
+		m.files = append(m.files, BrowserItem{FileItem: f, Deep: true})
+	}
+	m.deepLoaded = true
+}
+
 // containsIgnoreCase checks if s contains substr (case-insensitive)
 func containsIgnoreCase(s, substr string) bool {
 	return len(substr) == 0 ||
@@ -233,10 +1171,52 @@ func (m *Model) selectAll() {
 	}
 }
 
-// addSelectedToQueue adds all selected files and directories to the queue
-func (m *Model) addSelectedToQueue() {
+// startRange begins a range selection at the cursor
+func (m *Model) startRange() {
+	m.rangeMode = true
+	m.rangeStart = m.fileIndex
+	m.rangeBase = make(map[string]bool)
 	for _, f := range m.files {
 		if f.Selected {
+			m.rangeBase[f.Path] = true
+		}
+	}
+	m.applyRange()
+}
+
+// applyRange selects the visible files between rangeStart and the cursor.
+// The selection is recomputed from the anchor, so files the range no longer
+// covers go back to how they were when it started.
+func (m *Model) applyRange() {
+	lo, hi := m.rangeStart, m.fileIndex
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	// Visible files map to whether they are inside the range
+	inRange := make(map[string]bool)
+	for i, f := range m.filteredFiles() {
+		inRange[f.Path] = i >= lo && i <= hi
+	}
+	for j := range m.files {
+		p := m.files[j].Path
+		if in, visible := inRange[p]; visible {
+			m.files[j].Selected = m.rangeBase[p] || in
+		}
+	}
+}
+
+// addSelectedToQueue adds all selected files and directories to the queue.
+// With ExpandDirs, directories are expanded into their files by the returned
+// command; otherwise it is nil.
+func (m *Model) addSelectedToQueue() tea.Cmd {
+	var dirs []string
+	for _, f := range m.files {
+		if !f.Selected {
+			continue
+		}
+		if f.IsDir && m.config.ExpandDirs {
+			dirs = append(dirs, f.Path)
+		} else {
 			m.queue.Add(m.currentRemote, f.FileItem)
 		}
 	}
@@ -244,10 +1224,179 @@ func (m *Model) addSelectedToQueue() {
 	for i := range m.files {
 		m.files[i].Selected = false
 	}
+	if len(dirs) == 0 {
+		return nil
+	}
+	return m.expandDirs(dirs)
+}
+
+// dirsExpandedMsg is sent when queued directories have been expanded into their files
+type dirsExpandedMsg struct {
+	added int
+	err   error
+}
+
+// expandDirs returns a command that queues every file below dirs on the current remote
+func (m Model) expandDirs(dirs []string) tea.Cmd {
+	q := m.queue
+	remote := m.currentRemote
+	q.SetMaxDepth(m.config.MaxQueueDepth)
+	lister := func(dir string) ([]rclone.FileItem, error) {
+		items, _, err := rclone.ListFiles(remote, dir)
+		return items, err
+	}
+	return func() tea.Msg {
+		var errs []error
+		added := 0
+		for _, dir := range dirs {
+			n, err := q.AddRecursive(remote, dir, lister)
+			added += n
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return dirsExpandedMsg{added: added, err: errors.Join(errs...)}
+	}
+}
+
+// queueCheckedMsg is sent when a file about to be queued has been looked up on its remote
+type queueCheckedMsg struct {
+	remote string
+	file   rclone.FileItem
+	exists bool
+	err    error
+}
+
+// checkAndQueue returns a command that checks a file still exists before it
+// is queued, so a file deleted since listing fails now rather than at transfer time
+func checkAndQueue(remote string, file rclone.FileItem) tea.Cmd {
+	return func() tea.Msg {
+		exists, err := rclone.PathExists(context.Background(), remote, file.Path)
+		return queueCheckedMsg{remote: remote, file: file, exists: exists, err: err}
+	}
+}
+
+// typing reports whether a text input currently has focus
+func (m Model) typing() bool {
+	return m.filterMode || m.dateMode || m.queueSearchMode || m.tagMode || m.policyGlobMode || m.rcFlag != "" || m.state == StateNewRemote || m.state == StateDestinationInput || m.state == StatePasteFile || m.state == StateImportFilelist || m.state == StateCloneRemote ||
+		(m.state == StatePurgeConfirm && !m.purgeTyped)
+}
+
+// parseTags splits a comma-separated tag list, dropping empty entries
+func parseTags(s string) []string {
+	var tags []string
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// filelistImportedMsg is sent when a file list has been added to the queue
+type filelistImportedMsg struct {
+	added int
+	err   error
+}
+
+// importFilelist returns a command that queues the files named in the local
+// file list at listPath, relative to the current remote directory
+func (m Model) importFilelist(listPath string) tea.Cmd {
+	q := m.queue
+	remote := m.currentRemote
+	baseDir := m.currentPath
+	return func() tea.Msg {
+		f, err := os.Open(listPath)
+		if err != nil {
+			return filelistImportedMsg{err: fmt.Errorf("failed to open file list: %w", err)}
+		}
+		defer f.Close()
+
+		added, err := q.AddFromFilelist(f, remote, baseDir)
+		return filelistImportedMsg{added: added, err: err}
+	}
+}
+
+// queueSearchTag returns the tag of a "tag:name" queue search
+func (m Model) queueSearchTag() (string, bool) {
+	tag, ok := strings.CutPrefix(m.queueSearchInput.Value(), "tag:")
+	tag = strings.TrimSpace(tag)
+	return tag, ok && tag != ""
+}
+
+// queueMatchIndices returns the queue indices matching the current search
+func (m Model) queueMatchIndices() []int {
+	if tag, ok := m.queueSearchTag(); ok {
+		return m.queue.FindByTag(tag)
+	}
+	return m.queue.FindByName(m.queueSearchInput.Value())
+}
+
+// queueMatches returns the queue indices matching the current search as a set
+func (m Model) queueMatches() map[int]bool {
+	matches := make(map[int]bool)
+	for _, i := range m.queueMatchIndices() {
+		matches[i] = true
+	}
+	return matches
+}
+
+// batchQueue returns the items a download started now would include: those
+// carrying the searched tag, or the whole queue, minus paused items
+func (m Model) batchQueue() *queue.Queue {
+	if tag, ok := m.queueSearchTag(); ok {
+		return m.queue.FilterByTag(tag).Startable()
+	}
+	return m.queue.Startable()
+}
+
+// finishBatch removes the items that were transferred from the queue. Items
+// whose transfer failed stay queued so they can be retried.
+func (m *Model) finishBatch() {
+	if m.batch != nil && m.transferMgr != nil {
+		var done []queue.Item
+		for _, bt := range m.batchTransfers {
+			if t := m.transferMgr.Get(bt.id); t != nil && t.CurrentStatus() == rclone.StatusCompleted {
+				done = append(done, bt.item)
+			}
+		}
+		m.queue.RemoveItems(done)
+	}
+	if m.transferMgr != nil {
+		m.sessionBytes += m.transferMgr.TotalBytesTransferred()
+	}
+	m.batch = nil
+	m.batchTransfers = nil
+	m.transferMgr = nil
+}
+
+// sessionTransferred returns the bytes copied this session, including the running batch
+func (m Model) sessionTransferred() int64 {
+	total := m.sessionBytes
+	if m.transferMgr != nil {
+		total += m.transferMgr.TotalBytesTransferred()
+	}
+	return total
+}
+
+// maybeAutoStart starts downloads once the queue reaches the configured batch size
+func (m *Model) maybeAutoStart() tea.Cmd {
+	// Mirrors can delete local files, so they always wait for confirmation
+	if m.config.BatchSize <= 0 || m.queue.Len() < m.config.BatchSize || m.transferMgr != nil || m.spacePending || m.queue.HasMirror() {
+		return nil
+	}
+	m.batch = m.queue.Startable()
+	if m.batch.Len() == 0 {
+		return nil
+	}
+	m.autoStarted = true
+	m.state = StateTransferView
+	return m.startDownloads()
 }
 
 // enterDirectory enters a directory
 func (m *Model) enterDirectory(dir string) {
+	m.cursorHistory[m.currentRemote+":"+m.currentPath] = m.fileIndex
 	m.pathStack = append(m.pathStack, m.currentPath)
 	if m.currentPath == "" {
 		m.currentPath = dir
@@ -255,16 +1404,31 @@ func (m *Model) enterDirectory(dir string) {
 		m.currentPath = m.currentPath + "/" + dir
 	}
 	m.fileIndex = 0
+	m.rangeMode = false
 	m.filterText = ""
 	m.filterInput.SetValue("")
 }
 
+// restoreCursor moves the cursor back to where it was when the current
+// directory was left for a subdirectory. The position is used once, and
+// dropped if the listing has since shrunk past it.
+func (m *Model) restoreCursor() {
+	key := m.currentRemote + ":" + m.currentPath
+	if i, ok := m.cursorHistory[key]; ok {
+		delete(m.cursorHistory, key)
+		if i < len(m.files) {
+			m.fileIndex = i
+		}
+	}
+}
+
 // goBack navigates to the parent directory
 func (m *Model) goBack() bool {
 	if len(m.pathStack) > 0 {
 		m.currentPath = m.pathStack[len(m.pathStack)-1]
 		m.pathStack = m.pathStack[:len(m.pathStack)-1]
 		m.fileIndex = 0
+		m.rangeMode = false
 		m.filterText = ""
 		m.filterInput.SetValue("")
 		return true
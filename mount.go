@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"rcloneb/rclone"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// staleMountsMsg carries the rclone mounts found at startup whose rclone
+// process has died, leaving the mount point unusable
+type staleMountsMsg struct {
+	mounts []rclone.MountInfo
+}
+
+// mountsUnmountedMsg is sent when unmounting the stale mounts has finished
+type mountsUnmountedMsg struct {
+	remaining []rclone.MountInfo // Mounts that could not be unmounted
+	err       error
+}
+
+// checkStaleMounts returns a command that looks for dead rclone mounts
+func checkStaleMounts() tea.Cmd {
+	return func() tea.Msg {
+		mounts, err := rclone.StaleMounts()
+		if err != nil || len(mounts) == 0 {
+			return nil
+		}
+		return staleMountsMsg{mounts: mounts}
+	}
+}
+
+// unmountStale returns a command that unmounts every stale mount
+func (m Model) unmountStale() tea.Cmd {
+	mounts := m.staleMounts
+	return func() tea.Msg {
+		var remaining []rclone.MountInfo
+		var errs []error
+		for _, mnt := range mounts {
+			if err := rclone.Unmount(mnt.MountPoint); err != nil {
+				remaining = append(remaining, mnt)
+				errs = append(errs, err)
+			}
+		}
+		return mountsUnmountedMsg{remaining: remaining, err: errors.Join(errs...)}
+	}
+}
+
+// unmountConfirmView asks whether to unmount the stale mounts
+func (m Model) unmountConfirmView() string {
+	var b strings.Builder
+	b.WriteString(warningStyle.Render("These rclone mounts no longer respond:"))
+	b.WriteString("\n\n")
+	for _, mnt := range m.staleMounts {
+		b.WriteString(fmt.Sprintf("  %s (%s)\n", mnt.MountPoint, mnt.Remote))
+	}
+	if len(m.staleMounts) == 1 {
+		b.WriteString("\nUnmount it? [y/N]\n\n")
+	} else {
+		b.WriteString(fmt.Sprintf("\nUnmount all %d? [y/N]\n\n", len(m.staleMounts)))
+	}
+	b.WriteString(helpStyle.Render("y: unmount • any other key: cancel"))
+	return helpBoxStyle.Render(b.String())
+}
+
+// staleMountsHint returns the remote select footer entry for stale mounts
+func (m Model) staleMountsHint() string {
+	if len(m.staleMounts) == 0 {
+		return ""
+	}
+	if len(m.staleMounts) == 1 {
+		return fmt.Sprintf(" • U: unmount stale mount %s", m.staleMounts[0].MountPoint)
+	}
+	return fmt.Sprintf(" • U: unmount all %d stale mounts", len(m.staleMounts))
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"rcloneb/rclone"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Focus positions in the new remote form; the text inputs come first
+const (
+	newRemoteName = iota
+	newRemoteType
+	newRemoteOptions
+	newRemoteDetect
+	newRemoteCreate
+	newRemoteFocusCount
+)
+
+// remoteCreatedMsg is sent when "rclone config create" finishes
+type remoteCreatedMsg struct {
+	name  string
+	oauth bool // The remote still needs authorizing in a browser
+	err   error
+}
+
+// backendsLoadedMsg is sent when the available backend types are known
+type backendsLoadedMsg struct {
+	backends []rclone.BackendInfo
+	err      error
+}
+
+// backendSuggestions is the number of backend types listed under the type field
+const backendSuggestions = 6
+
+// loadBackends returns a command that lists the available backend types
+func loadBackends() tea.Cmd {
+	return func() tea.Msg {
+		backends, err := rclone.ListBackends()
+		return backendsLoadedMsg{backends: backends, err: err}
+	}
+}
+
+// matchingBackends returns the backend types whose name or description contains
+// the text typed in the type field
+func (m Model) matchingBackends() []rclone.BackendInfo {
+	typed := strings.TrimSpace(m.newRemoteInputs[newRemoteType].Value())
+	var matches []rclone.BackendInfo
+	for _, b := range m.backends {
+		switch {
+		case strings.EqualFold(b.Name, typed):
+			// An exact name is what enter should pick
+			matches = append([]rclone.BackendInfo{b}, matches...)
+		case containsIgnoreCase(b.Name, typed) || containsIgnoreCase(b.Description, typed):
+			matches = append(matches, b)
+		}
+	}
+	return matches
+}
+
+// chooseBackend fills the type field with a backend and hints at its required options
+func (m *Model) chooseBackend(b rclone.BackendInfo) {
+	m.newRemoteInputs[newRemoteType].SetValue(b.Name)
+
+	var hint []string
+	for _, o := range b.RequiredOptions() {
+		hint = append(hint, o.Name+"=...")
+	}
+	if len(hint) > 0 {
+		m.newRemoteInputs[newRemoteOptions].Placeholder = strings.Join(hint, " ")
+	}
+}
+
+// newRemoteInputs creates the text inputs of the new remote form
+func newRemoteInputs() []textinput.Model {
+	name := textinput.New()
+	name.Prompt = "Name:    "
+	name.Placeholder = "myremote"
+
+	typ := textinput.New()
+	typ.Prompt = "Type:    "
+	typ.Placeholder = "s3, drive, sftp..."
+
+	opts := textinput.New()
+	opts.Prompt = "Options: "
+	opts.Placeholder = "key=value key=value"
+
+	return []textinput.Model{name, typ, opts}
+}
+
+// openNewRemote shows an empty new remote form
+func (m *Model) openNewRemote() tea.Cmd {
+	m.newRemoteInputs = newRemoteInputs()
+	m.newRemoteFocus = newRemoteName
+	m.backendIndex = 0
+	m.state = StateNewRemote
+	cmd := m.focusNewRemote(newRemoteName)
+	if m.backends == nil {
+		return tea.Batch(cmd, loadBackends())
+	}
+	return cmd
+}
+
+// focusNewRemote moves focus to a form position, focusing its text input if it has one
+func (m *Model) focusNewRemote(pos int) tea.Cmd {
+	m.newRemoteFocus = (pos + newRemoteFocusCount) % newRemoteFocusCount
+	var cmd tea.Cmd
+	for i := range m.newRemoteInputs {
+		if i == m.newRemoteFocus {
+			cmd = m.newRemoteInputs[i].Focus()
+		} else {
+			m.newRemoteInputs[i].Blur()
+		}
+	}
+	return cmd
+}
+
+// applyCredential pre-fills the form from detected credentials; a name the
+// user already typed is kept
+func (m *Model) applyCredential(c detectedCredential) {
+	if m.newRemoteInputs[newRemoteName].Value() == "" {
+		m.newRemoteInputs[newRemoteName].SetValue(c.Name)
+	}
+	m.newRemoteInputs[newRemoteType].SetValue(c.Type)
+
+	pairs := make([]string, 0, len(c.Options))
+	for k, v := range c.Options {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	m.newRemoteInputs[newRemoteOptions].SetValue(strings.Join(pairs, " "))
+}
+
+// parseRemoteOptions parses space-separated key=value pairs
+func parseRemoteOptions(s string) (map[string]string, error) {
+	opts := make(map[string]string)
+	for _, field := range strings.Fields(s) {
+		k, v, ok := strings.Cut(field, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("option %q is not in key=value form", field)
+		}
+		opts[k] = v
+	}
+	return opts, nil
+}
+
+// createRemote returns a command that creates the remote described by the form
+func (m Model) createRemote() tea.Cmd {
+	name := strings.TrimSuffix(strings.TrimSpace(m.newRemoteInputs[newRemoteName].Value()), ":")
+	backend := strings.TrimSpace(m.newRemoteInputs[newRemoteType].Value())
+	opts, err := parseRemoteOptions(m.newRemoteInputs[newRemoteOptions].Value())
+
+	return func() tea.Msg {
+		switch {
+		case err != nil:
+			return remoteCreatedMsg{name: name, err: err}
+		case name == "":
+			return remoteCreatedMsg{err: fmt.Errorf("remote name is required")}
+		case backend == "":
+			return remoteCreatedMsg{name: name, err: fmt.Errorf("remote type is required")}
+		}
+		// Without a token in the options, OAuth backends must be authorized next
+		_, hasToken := opts["token"]
+		oauth := rclone.NeedsOAuth(backend) && !hasToken
+		return remoteCreatedMsg{name: name, oauth: oauth, err: rclone.CreateRemote(name, backend, opts)}
+	}
+}
+
+// cloneRemote returns a command that copies the configuration of src to a new remote
+func (m Model) cloneRemote(src string) tea.Cmd {
+	dst := strings.TrimSuffix(strings.TrimSpace(m.cloneInput.Value()), ":")
+	exists := false
+	for _, r := range m.remotes {
+		if r == dst {
+			exists = true
+		}
+	}
+
+	return func() tea.Msg {
+		switch {
+		case dst == "":
+			return remoteCreatedMsg{err: fmt.Errorf("remote name is required")}
+		case exists:
+			return remoteCreatedMsg{name: dst, err: fmt.Errorf("remote %s already exists", dst)}
+		}
+		return remoteCreatedMsg{name: dst, err: rclone.CloneRemote(src, dst)}
+	}
+}
+
+// newRemoteView renders the new remote form
+func (m Model) newRemoteView() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("New Remote"))
+	b.WriteString("\n\n")
+
+	for i, in := range m.newRemoteInputs {
+		b.WriteString(in.View())
+		b.WriteString("\n")
+		if i == newRemoteType && m.newRemoteFocus == newRemoteType {
+			b.WriteString(m.backendSelectorView())
+		}
+	}
+	b.WriteString("\n")
+
+	buttons := []struct {
+		pos   int
+		label string
+	}{
+		{newRemoteDetect, "Detect existing credentials"},
+		{newRemoteCreate, "Create"},
+	}
+	for _, btn := range buttons {
+		label := "[ " + btn.label + " ]"
+		if m.newRemoteFocus == btn.pos {
+			b.WriteString(selectedStyle.Render(label))
+		} else {
+			b.WriteString(normalStyle.Render(label))
+		}
+		b.WriteString("  ")
+	}
+	b.WriteString("\n")
+
+	if m.creatingRemote {
+		b.WriteString("\n")
+		b.WriteString(m.spinner.View())
+		b.WriteString(" Creating remote...")
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("tab/↑/↓: move (↑/↓ pick a type in the type field) • enter: next/press • esc: cancel"))
+
+	return b.String()
+}
+
+// backendSelectorView lists the backend types matching the type field, scrolled
+// to keep the highlighted one visible
+func (m Model) backendSelectorView() string {
+	matches := m.matchingBackends()
+	if len(matches) == 0 {
+		return ""
+	}
+
+	start := 0
+	if m.backendIndex >= backendSuggestions {
+		start = m.backendIndex - backendSuggestions + 1
+	}
+	end := start + backendSuggestions
+	if end > len(matches) {
+		end = len(matches)
+	}
+
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		line := fmt.Sprintf("   %-16s %s", matches[i].Name, matches[i].Description)
+		if i == m.backendIndex {
+			b.WriteString(selectedStyle.Render(line))
+		} else {
+			b.WriteString(normalStyle.Render(line))
+		}
+		b.WriteString("\n")
+	}
+	if len(matches) > end {
+		b.WriteString(helpStyle.Render(fmt.Sprintf("   ... %d more", len(matches)-end)))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// configWizardView renders the list of detected credentials
+func (m Model) configWizardView() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Detected Credentials"))
+	b.WriteString("\n\n")
+
+	for i, c := range m.detected {
+		line := fmt.Sprintf(" %-14s %s", c.Type, c.Label)
+		if i == m.detectedIndex {
+			b.WriteString(selectedStyle.Render(line))
+		} else {
+			b.WriteString(normalStyle.Render(line))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("j/k: navigate • enter: use these settings • esc: back"))
+
+	return b.String()
+}
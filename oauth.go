@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"rcloneb/rclone"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// oauthStartedMsg is sent once rclone has printed the authorization URL
+type oauthStartedMsg struct {
+	remote string
+	url    string
+	done   <-chan error
+	err    error
+}
+
+// oauthDoneMsg is sent when the browser authorization has finished
+type oauthDoneMsg struct {
+	remote string
+	err    error
+}
+
+// startOAuth shows the authorization screen and returns a command that asks
+// rclone to authorize remote in a browser
+func (m *Model) startOAuth(remote string) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.oauthRemote = remote
+	m.oauthURL = ""
+	m.oauthCancel = cancel
+	m.state = StateAuthorizing
+
+	start := func() tea.Msg {
+		url, done, err := rclone.StartOAuth(ctx, remote)
+		return oauthStartedMsg{remote: remote, url: url, done: done, err: err}
+	}
+	return tea.Batch(start, m.spinner.Tick)
+}
+
+// waitOAuth returns a command that waits for the authorization to finish
+func waitOAuth(remote string, done <-chan error) tea.Cmd {
+	return func() tea.Msg {
+		return oauthDoneMsg{remote: remote, err: <-done}
+	}
+}
+
+// openURL opens url in the default web browser
+func openURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+// updateAuthorizing handles keys while waiting for browser authorization
+func (m Model) updateAuthorizing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key := msg.String(); key == "esc" || key == "q" {
+		// The done message returns to the remote list
+		if m.oauthCancel != nil {
+			m.oauthCancel()
+			m.oauthCancel = nil
+		}
+	}
+	return m, nil
+}
+
+// authorizingView renders the wait for browser authorization
+func (m Model) authorizingView() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Authorize " + m.oauthRemote))
+	b.WriteString("\n\n")
+	b.WriteString(m.spinner.View())
+	if m.oauthURL == "" {
+		b.WriteString(" Starting rclone...")
+	} else {
+		b.WriteString(" Authorize in browser…\n\n")
+		b.WriteString(helpStyle.Render("If no browser opened, visit " + m.oauthURL))
+	}
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("esc: cancel"))
+	return b.String()
+}
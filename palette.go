@@ -0,0 +1,236 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Command is an action that can be run from the command palette
+type Command struct {
+	Name        string
+	Description string
+	Fn          func(*Model) tea.Cmd
+}
+
+// paletteCommands returns every action available from the command palette
+func paletteCommands() []Command {
+	return []Command{
+		{
+			Name:        "Go to remotes",
+			Description: "Return to the remote selection list",
+			Fn: func(m *Model) tea.Cmd {
+				m.state = StateRemoteSelect
+				m.selectedIndex = 0
+				return nil
+			},
+		},
+		{
+			Name:        "View queue",
+			Description: "Queue selected files and open the download queue",
+			Fn: func(m *Model) tea.Cmd {
+				expand := m.addSelectedToQueue()
+				m.state = StateQueueView
+				m.selectedIndex = 0
+				return expand
+			},
+		},
+		{
+			Name:        "Start downloads",
+			Description: "Preview and start downloading the queue",
+			Fn: func(m *Model) tea.Cmd {
+				m.batch = m.batchQueue()
+				if m.batch.Len() == 0 {
+					return nil
+				}
+				m.state = StateQueuePreview
+				m.previewFiles = nil
+				m.previewLoading = true
+				return tea.Batch(m.loadPreview(), m.spinner.Tick)
+			},
+		},
+		{
+			Name:        "Refresh listing",
+			Description: "Re-list the current directory, bypassing the cache",
+			Fn: func(m *Model) tea.Cmd {
+				if m.currentRemote == "" {
+					return nil
+				}
+				m.state = StateFileBrowser
+				m.loading = true
+				return tea.Batch(m.reloadFiles(), m.spinner.Tick)
+			},
+		},
+		{
+			Name:        "Filter files",
+			Description: "Filter the current directory by name",
+			Fn: func(m *Model) tea.Cmd {
+				if m.currentRemote == "" {
+					return nil
+				}
+				m.state = StateFileBrowser
+				m.filterMode = true
+				m.filterInput.Focus()
+				return m.loadDeepListing()
+			},
+		},
+		{
+			Name:        "Select all files",
+			Description: "Toggle selection of every visible file",
+			Fn: func(m *Model) tea.Cmd {
+				if m.state == StateFileBrowser {
+					m.selectAll()
+				}
+				return nil
+			},
+		},
+		{
+			Name:        "Toggle file details",
+			Description: "Show or hide the detail panel in the file browser",
+			Fn: func(m *Model) tea.Cmd {
+				m.showDetail = !m.showDetail
+				return nil
+			},
+		},
+		{
+			Name:        "Export disk usage",
+			Description: "Export the highlighted or current remote for ncdu and open it",
+			Fn: func(m *Model) tea.Cmd {
+				remote := m.currentRemote
+				if m.state == StateRemoteSelect && len(m.remotes) > 0 {
+					remote = m.remotes[m.selectedIndex]
+				}
+				if remote == "" {
+					return nil
+				}
+				return m.exportDiskUsage(remote)
+			},
+		},
+		{
+			Name:        "Serve as Restic backend",
+			Description: "Start or stop a restic REST server for the highlighted or current remote",
+			Fn: func(m *Model) tea.Cmd {
+				remote, path := m.currentRemote, m.currentPath
+				if m.state == StateRemoteSelect && len(m.remotes) > 0 {
+					remote, path = m.remotes[m.selectedIndex], ""
+				}
+				if remote == "" {
+					return nil
+				}
+				if _, running := m.servers["restic"]; running {
+					return m.toggleRestic(remote, path)
+				}
+				addr := m.config.ResticAddr
+				return tea.Batch(m.toggleRestic(remote, path), m.showFlash("restic repository at rest:http://"+addr+"/", 5*time.Second))
+			},
+		},
+		{
+			Name:        "Authorize remote",
+			Description: "Reconnect the highlighted or current remote, authorizing it in a browser",
+			Fn: func(m *Model) tea.Cmd {
+				remote := m.currentRemote
+				if m.state == StateRemoteSelect && len(m.remotes) > 0 {
+					remote = m.remotes[m.selectedIndex]
+				}
+				if remote == "" {
+					return nil
+				}
+				return m.startOAuth(remote)
+			},
+		},
+		{
+			Name:        "Quit",
+			Description: "Cancel transfers and exit rcloneb",
+			Fn: func(m *Model) tea.Cmd {
+				if m.transferCancel != nil {
+					m.transferCancel()
+				}
+				return tea.Quit
+			},
+		},
+	}
+}
+
+// openPalette shows the command palette over the current view
+func (m *Model) openPalette() {
+	m.paletteReturn = m.state
+	m.state = StateCommandPalette
+	m.paletteIndex = 0
+	m.paletteInput.SetValue("")
+	m.paletteInput.Focus()
+}
+
+// closePalette hides the command palette and returns to the previous view
+func (m *Model) closePalette() tea.Cmd {
+	m.state = m.paletteReturn
+	m.paletteInput.Blur()
+
+	// The transfer view stops ticking while hidden, so restart it
+	if m.state == StateTransferView && m.transferMgr != nil {
+		return tickCmd()
+	}
+	return nil
+}
+
+// paletteMatches returns commands matching the palette query, most recently used first
+func (m Model) paletteMatches() []Command {
+	query := m.paletteInput.Value()
+
+	var recent, rest []Command
+	for _, c := range paletteCommands() {
+		if !fuzzyMatch(c.Name, query) && !fuzzyMatch(c.Description, query) {
+			continue
+		}
+		if m.recentRank(c.Name) >= 0 {
+			recent = append(recent, c)
+		} else {
+			rest = append(rest, c)
+		}
+	}
+
+	// Order recently used commands by recency
+	for i := 1; i < len(recent); i++ {
+		for j := i; j > 0 && m.recentRank(recent[j].Name) < m.recentRank(recent[j-1].Name); j-- {
+			recent[j], recent[j-1] = recent[j-1], recent[j]
+		}
+	}
+	return append(recent, rest...)
+}
+
+// recentRank returns the recency position of a command, or -1 if never used
+func (m Model) recentRank(name string) int {
+	for i, n := range m.paletteRecent {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// runPaletteCommand executes a command and records it as most recently used
+func (m *Model) runPaletteCommand(c Command) tea.Cmd {
+	recent := []string{c.Name}
+	for _, n := range m.paletteRecent {
+		if n != c.Name {
+			recent = append(recent, n)
+		}
+	}
+	m.paletteRecent = recent
+
+	tick := m.closePalette()
+	return tea.Batch(tick, c.Fn(m))
+}
+
+// fuzzyMatch reports whether all characters of query appear in s in order (case-insensitive)
+func fuzzyMatch(s, query string) bool {
+	s = strings.ToLower(s)
+	for _, r := range strings.ToLower(query) {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(string(r)):]
+	}
+	return true
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"rcloneb/queue"
+	"rcloneb/rclone"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// progressSaveInterval is how often unfinished transfers are written to disk
+const progressSaveInterval = 5 * time.Second
+
+// progressRecord is an unfinished transfer saved so it can be resumed after a restart
+type progressRecord struct {
+	TransferID  string `json:"transferID"`
+	Source      string `json:"source"`
+	Dest        string `json:"dest"`
+	BytesCopied int64  `json:"bytesCopied"`
+
+	// Enough of the queue item to add it back
+	Remote  string `json:"remote"`
+	Path    string `json:"path"`
+	Name    string `json:"name"`
+	Size    int64  `json:"size"`
+	IsDir   bool   `json:"isDir"`
+	ModTime string `json:"modTime,omitempty"`
+	Mirror  bool   `json:"mirror,omitempty"`
+	Policy  int    `json:"policy,omitempty"`
+}
+
+// progressSaveMsg triggers a periodic write of progress.json
+type progressSaveMsg struct{}
+
+// interruptedMsg carries transfers left unfinished by a previous run
+type interruptedMsg struct {
+	records []progressRecord
+}
+
+// progressPath returns the location of progress.json
+func progressPath() string {
+	return filepath.Join(dataDir(), "progress.json")
+}
+
+// saveProgressTick schedules the next progress.json write
+func saveProgressTick() tea.Cmd {
+	return tea.Tick(progressSaveInterval, func(time.Time) tea.Msg {
+		return progressSaveMsg{}
+	})
+}
+
+// progressRecords returns the queue items whose transfers have not finished
+func (m Model) progressRecords() []progressRecord {
+	cwd := downloadDir()
+
+	var records []progressRecord
+	for _, bt := range m.batchTransfers {
+		item := bt.item
+		t := m.transferMgr.Get(bt.id)
+		if t == nil {
+			continue
+		}
+		if status := t.CurrentStatus(); status != rclone.StatusPending && status != rclone.StatusInProgress && status != rclone.StatusRateLimited {
+			continue
+		}
+		records = append(records, progressRecord{
+			TransferID:  bt.id,
+			Source:      t.Source,
+			Dest:        itemDest(item, cwd),
+			BytesCopied: t.CopiedBytes(),
+			Remote:      item.Remote,
+			Path:        item.Path,
+			Name:        item.Name,
+			Size:        item.Size,
+			IsDir:       item.IsDir,
+			ModTime:     item.ModTime,
+			Mirror:      item.Mirror,
+			Policy:      int(item.Policy),
+		})
+	}
+	return records
+}
+
+// saveProgress writes the unfinished transfers to progress.json, removing the
+// file once nothing is left to resume
+func saveProgress(records []progressRecord) error {
+	p := progressPath()
+	if len(records) == 0 {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove progress file: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode progress: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	// Write to a temporary file first so a kill mid-write leaves the old file intact
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write progress file: %w", err)
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		return fmt.Errorf("failed to write progress file: %w", err)
+	}
+	return nil
+}
+
+// saveProgressCmd returns a command that writes records to progress.json off
+// the main loop
+func saveProgressCmd(records []progressRecord, verbose bool) tea.Cmd {
+	return func() tea.Msg {
+		if err := saveProgress(records); err != nil && verbose {
+			log.Printf("saving progress: %v", err)
+		}
+		return nil
+	}
+}
+
+// checkInterrupted returns a command that loads progress.json and keeps the
+// transfers whose source still exists on the remote
+func checkInterrupted() tea.Cmd {
+	return func() tea.Msg {
+		data, err := os.ReadFile(progressPath())
+		if err != nil {
+			return nil
+		}
+
+		var records []progressRecord
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		var found []progressRecord
+		for _, r := range records {
+			if _, err := rclone.Stat(ctx, r.Remote, r.Path); err == nil {
+				found = append(found, r)
+			}
+		}
+		return interruptedMsg{records: found}
+	}
+}
+
+// resumeInterrupted adds interrupted transfers back to the queue
+func (m *Model) resumeInterrupted() {
+	for _, r := range m.interrupted {
+		m.queue.Restore(queue.Item{
+			Remote:      r.Remote,
+			Path:        r.Path,
+			Name:        r.Name,
+			Size:        r.Size,
+			IsDir:       r.IsDir,
+			ModTime:     r.ModTime,
+			Mirror:      r.Mirror,
+			Policy:      queue.ConflictPolicy(r.Policy),
+			LocalPath:   r.Dest,
+			BytesCopied: r.BytesCopied,
+		})
+	}
+}
+
+// resumePromptView renders the offer to resume interrupted transfers
+func (m Model) resumePromptView() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Interrupted Transfers"))
+	b.WriteString("\n\n")
+
+	for _, r := range m.interrupted {
+		line := fmt.Sprintf("%s → %s", r.Source, r.Dest)
+		if r.Size > 0 {
+			line += fmt.Sprintf("  (%s of %s)", rclone.FormatSize(r.BytesCopied), rclone.FormatSize(r.Size))
+		}
+		b.WriteString(queueItemStyle.Render(line))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf("Resume %d interrupted transfers? [y/N]", len(m.interrupted)))
+	b.WriteString("\n")
+
+	return b.String()
+}
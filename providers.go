@@ -0,0 +1,51 @@
+package main
+
+import "sort"
+
+// providerLabels maps rclone backend types to the names shown in section headers
+var providerLabels = map[string]string{
+	"alias":                "Alias",
+	"azureblob":            "Azure Blob Storage",
+	"b2":                   "Backblaze B2",
+	"box":                  "Box",
+	"crypt":                "Encrypted",
+	"drive":                "Google Drive",
+	"dropbox":              "Dropbox",
+	"ftp":                  "FTP",
+	"google cloud storage": "Google Cloud Storage",
+	"google photos":        "Google Photos",
+	"http":                 "HTTP",
+	"local":                "Local",
+	"mega":                 "MEGA",
+	"onedrive":             "OneDrive",
+	"pcloud":               "pCloud",
+	"s3":                   "S3",
+	"sftp":                 "SFTP",
+	"smb":                  "SMB",
+	"swift":                "OpenStack Swift",
+	"union":                "Union",
+	"webdav":               "WebDAV",
+}
+
+// providerLabel returns the display name of a backend type
+func providerLabel(typ string) string {
+	if label, ok := providerLabels[typ]; ok {
+		return label
+	}
+	if typ == "" {
+		return "Other"
+	}
+	return typ
+}
+
+// sortedProviderTypes returns the backend types of groups ordered by display name
+func sortedProviderTypes(groups map[string][]string) []string {
+	types := make([]string, 0, len(groups))
+	for typ := range groups {
+		types = append(types, typ)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return providerLabel(types[i]) < providerLabel(types[j])
+	})
+	return types
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"rcloneb/rclone"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// purgedMsg is sent when "rclone purge" finishes
+type purgedMsg struct {
+	remotePath string
+	err        error
+}
+
+// openPurgeConfirm starts the confirmation for purging dir
+func (m *Model) openPurgeConfirm(dir rclone.FileItem) tea.Cmd {
+	m.purgeTarget = dir
+	m.purgeTyped = false
+	m.purgeInput.SetValue("")
+	m.state = StatePurgeConfirm
+	return tea.Batch(m.purgeInput.Focus(), textinput.Blink)
+}
+
+// updatePurgeConfirm handles the two confirmation steps before a purge: typing
+// the directory name, then a final y/N
+func (m Model) updatePurgeConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "esc" {
+		m.purgeInput.Blur()
+		m.state = StateFileBrowser
+		return m, nil
+	}
+
+	if m.purgeTyped {
+		switch msg.String() {
+		case "y", "Y":
+			m.state = StateFileBrowser
+			return m, m.purge()
+		case "n", "N", "enter":
+			m.state = StateFileBrowser
+		}
+		return m, nil
+	}
+
+	if msg.String() == "enter" {
+		if strings.TrimSpace(m.purgeInput.Value()) != m.purgeTarget.Name {
+			return m, m.showFlash("Name does not match; nothing was deleted", 2*time.Second)
+		}
+		m.purgeInput.Blur()
+		m.purgeTyped = true
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.purgeInput, cmd = m.purgeInput.Update(msg)
+	return m, cmd
+}
+
+// purge returns a command that purges the confirmed directory and records it in the history log
+func (m Model) purge() tea.Cmd {
+	remote := m.currentRemote
+	dir := m.purgeTarget.Path
+	verbose := m.config.Verbose
+
+	return func() tea.Msg {
+		remotePath := remote + ":" + dir
+		err := rclone.Purge(context.Background(), remote, dir)
+		event := "purge " + remotePath
+		if err != nil {
+			event += " failed: " + err.Error()
+		}
+		if herr := appendHistory(event); herr != nil && verbose {
+			log.Printf("writing history: %v", herr)
+		}
+		return purgedMsg{remotePath: remotePath, err: err}
+	}
+}
+
+// purgePromptView renders the current purge confirmation step
+func (m Model) purgePromptView() string {
+	var b strings.Builder
+	remotePath := m.currentRemote + ":" + m.purgeTarget.Path
+
+	b.WriteString(warningStyle.Render(fmt.Sprintf("Purge %s deletes it and everything inside it.", remotePath)))
+	b.WriteString("\n")
+	if m.purgeTyped {
+		b.WriteString(fmt.Sprintf("Permanently delete %s? [y/N]", remotePath))
+		return b.String()
+	}
+	b.WriteString("Type the directory name to confirm deletion:\n")
+	b.WriteString(filterPromptStyle.Render(m.purgeInput.View()))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("enter: confirm • esc: cancel"))
+	return b.String()
+}
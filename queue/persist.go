@@ -0,0 +1,109 @@
+package queue
+
+import "time"
+
+// DefaultPersistInterval is how long a change waits before the queue is saved
+const DefaultPersistInterval = time.Second
+
+// persister saves the queue in the background after it changes
+type persister struct {
+	save     func([]Item)
+	interval time.Duration
+	dirty    chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// Persist saves the queue with save whenever it changes, at most once per
+// persist interval, until Close. Calling it again replaces save.
+func (q *Queue) Persist(save func([]Item)) {
+	q.Close()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	interval := DefaultPersistInterval
+	if q.persist != nil {
+		interval = q.persist.interval
+	}
+	p := &persister{
+		save:     save,
+		interval: interval,
+		dirty:    make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	q.persist = p
+	go q.persistLoop(p)
+}
+
+// PersistInterval sets how long changes are collected before the queue is
+// saved. Slow disks may want several seconds; zero restores the default.
+func (q *Queue) PersistInterval(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultPersistInterval
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.persist == nil {
+		q.persist = &persister{}
+	}
+	q.persist.interval = interval
+}
+
+// Close stops saving the queue, first saving any change still waiting
+func (q *Queue) Close() {
+	q.mu.Lock()
+	p := q.persist
+	running := p != nil && p.stop != nil
+	if running {
+		// Keep the interval for a later Persist
+		q.persist = &persister{interval: p.interval}
+	}
+	q.mu.Unlock()
+
+	if running {
+		close(p.stop)
+		<-p.done
+	}
+}
+
+// changed schedules a save of the queue; q.mu must be held
+func (q *Queue) changed() {
+	if q.persist == nil || q.persist.dirty == nil {
+		return
+	}
+	select {
+	case q.persist.dirty <- struct{}{}:
+	default:
+		// A save is already due
+	}
+}
+
+// persistLoop saves the queue a persist interval after the first unsaved
+// change, and once more on Close if a change is still waiting
+func (q *Queue) persistLoop(p *persister) {
+	defer close(p.done)
+
+	var due <-chan time.Time
+	for {
+		select {
+		case <-p.dirty:
+			if due == nil {
+				q.mu.Lock()
+				interval := p.interval
+				q.mu.Unlock()
+				due = time.After(interval)
+			}
+		case <-due:
+			due = nil
+			p.save(q.Snapshot())
+		case <-p.stop:
+			if due != nil || len(p.dirty) > 0 {
+				p.save(q.Snapshot())
+			}
+			return
+		}
+	}
+}
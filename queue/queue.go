@@ -1,7 +1,17 @@
 package queue
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
 	"rcloneb/rclone"
+	"slices"
+	"strings"
 	"sync"
 )
 
@@ -15,23 +25,57 @@ const (
 	StatusError
 )
 
+// ConflictPolicy decides what happens when a download's destination already exists
+type ConflictPolicy int
+
+const (
+	PolicyOverwrite ConflictPolicy = iota // Replace files that differ, rclone's default
+	PolicySkip                            // Leave existing files alone (--ignore-existing)
+	PolicyRenameNew                       // Download under a new name such as "name (1).ext"
+)
+
+// Badge returns the short label shown next to queue items
+func (p ConflictPolicy) Badge() string {
+	switch p {
+	case PolicySkip:
+		return "[SK]"
+	case PolicyRenameNew:
+		return "[RN]"
+	default:
+		return "[OW]"
+	}
+}
+
 // Item represents a file or directory in the download queue
 type Item struct {
-	Remote   string
-	Path     string
-	Name     string
-	Size     int64
-	IsDir    bool
-	Status   ItemStatus
-	Progress float64
-	Speed    string
-	Error    error
+	Remote      string
+	Path        string
+	Name        string
+	Size        int64
+	IsDir       bool
+	ModTime     string // Remote modification time, RFC 3339
+	Mirror      bool   // Sync the directory, deleting local files missing on the remote
+	LocalPath   string // Destination directory; empty means the default download directory
+	BytesCopied int64  // Bytes already copied by an interrupted earlier run
+	Tags        []string
+	Held        bool // Paused; skipped when downloads start
+	Policy      ConflictPolicy
+	Status      ItemStatus
+	Progress    float64
+	Speed       string
+	Error       error `json:"-"`
 }
 
+// DefaultMaxDepth is how many directory levels AddRecursive descends by default
+const DefaultMaxDepth = 10
+
 // Queue manages the download queue
 type Queue struct {
-	items []Item
-	mu    sync.Mutex
+	items       []Item
+	destination string // LocalPath given to newly added items
+	maxDepth    int    // Directory levels AddRecursive descends; zero means DefaultMaxDepth
+	persist     *persister
+	mu          sync.Mutex
 }
 
 // New creates a new download queue
@@ -54,13 +98,147 @@ func (q *Queue) Add(remote string, file rclone.FileItem) {
 	}
 
 	q.items = append(q.items, Item{
-		Remote: remote,
-		Path:   file.Path,
-		Name:   file.Name,
-		Size:   file.Size,
-		IsDir:  file.IsDir,
-		Status: StatusPending,
+		Remote:    remote,
+		Path:      file.Path,
+		Name:      file.Name,
+		Size:      file.Size,
+		IsDir:     file.IsDir,
+		ModTime:   file.ModTime,
+		LocalPath: q.destination,
+		Status:    StatusPending,
+	})
+	q.changed()
+}
+
+// AddFromFilelist queues the files listed one per line in r, relative to
+// baseDir on remote. Blank lines and lines starting with # are ignored. Paths
+// that cannot be found are skipped and reported together in the error; the
+// count of items added is returned either way.
+func (q *Queue) AddFromFilelist(r io.Reader, remote string, baseDir string) (int, error) {
+	var errs []error
+	added := 0
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		filePath := strings.TrimPrefix(path.Join(baseDir, line), "/")
+		item, err := rclone.Stat(context.Background(), remote, filePath)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !q.Contains(remote, filePath) {
+			q.Add(remote, item)
+			added++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to read file list: %w", err))
+	}
+	return added, errors.Join(errs...)
+}
+
+// SetMaxDepth sets how many directory levels AddRecursive descends
+func (q *Queue) SetMaxDepth(depth int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.maxDepth = depth
+}
+
+// AddRecursive queues every file below path on remote, listing directories
+// with lister and descending at most the queue's maximum depth. Each path is
+// listed once, so remotes that expose symlink cycles terminate. Directories
+// that cannot be listed are skipped and reported together in the error; the
+// count of files added is returned either way.
+func (q *Queue) AddRecursive(remote, path string, lister func(string) ([]rclone.FileItem, error)) (int, error) {
+	q.mu.Lock()
+	maxDepth := q.maxDepth
+	q.mu.Unlock()
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+
+	var errs []error
+	added := 0
+	visited := make(map[string]bool)
+
+	var walk func(dir string, depth int)
+	walk = func(dir string, depth int) {
+		if visited[dir] {
+			return
+		}
+		visited[dir] = true
+
+		items, err := lister(dir)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		for _, item := range items {
+			if item.IsDir {
+				if depth < maxDepth {
+					walk(item.Path, depth+1)
+				}
+				continue
+			}
+			if !q.Contains(remote, item.Path) {
+				q.Add(remote, item)
+				added++
+			}
+		}
+	}
+	walk(path, 1)
+	return added, errors.Join(errs...)
+}
+
+// AddMirror queues a directory to be mirrored rather than copied. An item
+// already in the queue is switched to a mirror.
+func (q *Queue) AddMirror(remote string, file rclone.FileItem) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i := range q.items {
+		if q.items[i].Remote == remote && q.items[i].Path == file.Path {
+			q.items[i].Mirror = true
+			q.changed()
+			return
+		}
+	}
+
+	q.items = append(q.items, Item{
+		Remote:    remote,
+		Path:      file.Path,
+		Name:      file.Name,
+		Size:      file.Size,
+		IsDir:     file.IsDir,
+		ModTime:   file.ModTime,
+		Mirror:    true,
+		LocalPath: q.destination,
+		Status:    StatusPending,
 	})
+	q.changed()
+}
+
+// Restore re-adds an item from an interrupted earlier run as pending
+func (q *Queue) Restore(item Item) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, existing := range q.items {
+		if existing.Remote == item.Remote && existing.Path == item.Path {
+			return
+		}
+	}
+
+	item.Status = StatusPending
+	item.Progress = 0
+	item.Error = nil
+	q.items = append(q.items, item)
+	q.changed()
 }
 
 // Remove removes an item from the queue by index
@@ -70,6 +248,7 @@ func (q *Queue) Remove(index int) {
 
 	if index >= 0 && index < len(q.items) {
 		q.items = append(q.items[:index], q.items[index+1:]...)
+		q.changed()
 	}
 }
 
@@ -83,6 +262,20 @@ func (q *Queue) Items() []Item {
 	return result
 }
 
+// Snapshot returns a deep copy of the queue items as they are now, holding
+// the lock only while the items themselves are copied. Item slices such as
+// Tags are replaced rather than modified, so they are cloned afterwards.
+func (q *Queue) Snapshot() []Item {
+	q.mu.Lock()
+	result := slices.Clone(q.items)
+	q.mu.Unlock()
+
+	for i := range result {
+		result[i].Tags = slices.Clone(result[i].Tags)
+	}
+	return result
+}
+
 // Len returns the number of items in the queue
 func (q *Queue) Len() int {
 	q.mu.Lock()
@@ -90,11 +283,24 @@ func (q *Queue) Len() int {
 	return len(q.items)
 }
 
-// Clear removes all items from the queue
-func (q *Queue) Clear() {
+// Clear removes the items with any of the given statuses from the queue, or
+// every item when no status is given
+func (q *Queue) Clear(statuses ...ItemStatus) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	q.items = make([]Item, 0)
+	defer q.changed()
+	if len(statuses) == 0 {
+		q.items = make([]Item, 0)
+		return
+	}
+
+	kept := q.items[:0]
+	for _, item := range q.items {
+		if !slices.Contains(statuses, item.Status) {
+			kept = append(kept, item)
+		}
+	}
+	q.items = kept
 }
 
 // UpdateProgress updates the progress of an item
@@ -112,6 +318,20 @@ func (q *Queue) UpdateProgress(path string, progress float64, speed string) {
 	}
 }
 
+// SetSize sets the size of an item, for items queued before their size was known
+func (q *Queue) SetSize(remote, path string, size int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i := range q.items {
+		if q.items[i].Remote == remote && q.items[i].Path == path {
+			q.items[i].Size = size
+			q.changed()
+			break
+		}
+	}
+}
+
 // SetStatus sets the status of an item
 func (q *Queue) SetStatus(path string, status ItemStatus, err error) {
 	q.mu.Lock()
@@ -124,6 +344,7 @@ func (q *Queue) SetStatus(path string, status ItemStatus, err error) {
 			if status == StatusCompleted {
 				q.items[i].Progress = 100
 			}
+			q.changed()
 			break
 		}
 	}
@@ -155,6 +376,43 @@ func (q *Queue) HasPending() bool {
 	return false
 }
 
+// ChangeDestination sets the destination directory of every pending item and
+// of items added later. Items that are downloading or finished keep theirs.
+func (q *Queue) ChangeDestination(newDest string) error {
+	if strings.TrimSpace(newDest) == "" {
+		return fmt.Errorf("destination must not be empty")
+	}
+	newDest = filepath.Clean(newDest)
+	if info, err := os.Stat(newDest); err == nil && !info.IsDir() {
+		return fmt.Errorf("destination %s is not a directory", newDest)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.destination = newDest
+	for i := range q.items {
+		if q.items[i].Status == StatusPending {
+			q.items[i].LocalPath = newDest
+		}
+	}
+	q.changed()
+	return nil
+}
+
+// HasMirror returns true if any item will be mirrored
+func (q *Queue) HasMirror() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, item := range q.items {
+		if item.Mirror {
+			return true
+		}
+	}
+	return false
+}
+
 // TotalSize returns the total size of all items in the queue
 func (q *Queue) TotalSize() int64 {
 	q.mu.Lock()
@@ -167,6 +425,197 @@ func (q *Queue) TotalSize() int64 {
 	return total
 }
 
+// TotalSizeByStatus returns the total size of queue items grouped by status
+func (q *Queue) TotalSizeByStatus() map[ItemStatus]int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	totals := make(map[ItemStatus]int64)
+	for _, item := range q.items {
+		totals[item.Status] += item.Size
+	}
+	return totals
+}
+
+// ExtSummary counts the queue items sharing a file extension
+type ExtSummary struct {
+	Count      int
+	TotalBytes int64
+}
+
+// SummarizeByExtension groups queue items by lowercase file extension without
+// the dot. Directories are grouped as "[dir]" and files without an extension
+// as "[other]".
+func (q *Queue) SummarizeByExtension() map[string]ExtSummary {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	summary := make(map[string]ExtSummary)
+	for _, item := range q.items {
+		ext := "[dir]"
+		if !item.IsDir {
+			ext = strings.ToLower(strings.TrimPrefix(path.Ext(item.Name), "."))
+			if ext == "" {
+				ext = "[other]"
+			}
+		}
+		s := summary[ext]
+		s.Count++
+		s.TotalBytes += item.Size
+		summary[ext] = s
+	}
+	return summary
+}
+
+// FindByName returns the indices of items whose name contains name, ignoring case
+func (q *Queue) FindByName(name string) []int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if name == "" {
+		return nil
+	}
+	name = strings.ToLower(name)
+
+	var indices []int
+	for i, item := range q.items {
+		if strings.Contains(strings.ToLower(item.Name), name) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// FindByTag returns the indices of items carrying tag, ignoring case
+func (q *Queue) FindByTag(tag string) []int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var indices []int
+	for i, item := range q.items {
+		if item.HasTag(tag) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// FilterByTag returns a new queue holding copies of the items carrying tag
+func (q *Queue) FilterByTag(tag string) *Queue {
+	filtered := New()
+	for _, item := range q.Items() {
+		if item.HasTag(tag) {
+			filtered.items = append(filtered.items, item)
+		}
+	}
+	return filtered
+}
+
+// SetTags replaces the tags of an item by index
+func (q *Queue) SetTags(index int, tags []string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if index >= 0 && index < len(q.items) {
+		q.items[index].Tags = tags
+		q.changed()
+	}
+}
+
+// CyclePolicy moves an item by index to the next conflict policy
+func (q *Queue) CyclePolicy(index int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if index >= 0 && index < len(q.items) {
+		q.items[index].Policy = (q.items[index].Policy + 1) % (PolicyRenameNew + 1)
+		q.changed()
+	}
+}
+
+// BulkSetConflictPolicy sets the conflict policy of every item matching
+// filter, returning how many matched. filter is called with the queue
+// locked, so it must not use the queue.
+func (q *Queue) BulkSetConflictPolicy(policy ConflictPolicy, filter func(Item) bool) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	n := 0
+	for i := range q.items {
+		if filter(q.items[i]) {
+			q.items[i].Policy = policy
+			n++
+		}
+	}
+	if n > 0 {
+		q.changed()
+	}
+	return n
+}
+
+// ToggleHeld pauses the items at indices, or resumes them if all are already paused
+func (q *Queue) ToggleHeld(indices []int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	held := true
+	for _, i := range indices {
+		if i >= 0 && i < len(q.items) && !q.items[i].Held {
+			held = false
+			break
+		}
+	}
+	for _, i := range indices {
+		if i >= 0 && i < len(q.items) {
+			q.items[i].Held = !held
+		}
+	}
+	q.changed()
+}
+
+// RemoveItems removes every item matching one of items by remote and path
+func (q *Queue) RemoveItems(items []Item) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	kept := q.items[:0]
+	for _, existing := range q.items {
+		found := false
+		for _, item := range items {
+			if existing.Remote == item.Remote && existing.Path == item.Path {
+				found = true
+				break
+			}
+		}
+		if !found {
+			kept = append(kept, existing)
+		}
+	}
+	q.items = kept
+	q.changed()
+}
+
+// Startable returns a new queue holding copies of the items that are not held
+func (q *Queue) Startable() *Queue {
+	startable := New()
+	for _, item := range q.Items() {
+		if !item.Held {
+			startable.items = append(startable.items, item)
+		}
+	}
+	return startable
+}
+
+// HasTag reports whether the item carries tag, ignoring case
+func (item Item) HasTag(tag string) bool {
+	for _, t := range item.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
 // Contains checks if a path is already in the queue
 func (q *Queue) Contains(remote, path string) bool {
 	q.mu.Lock()
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"rcloneb/queue"
+)
+
+// queuePath returns the location of queue.json
+func queuePath() string {
+	return filepath.Join(dataDir(), "queue.json")
+}
+
+// saveQueue writes the queue to queue.json, removing the file once the queue
+// is empty
+func saveQueue(items []queue.Item) error {
+	p := queuePath()
+	if len(items) == 0 {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove queue file: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode queue: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	// Write to a temporary file first so a kill mid-write leaves the old file intact
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write queue file: %w", err)
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		return fmt.Errorf("failed to write queue file: %w", err)
+	}
+	return nil
+}
+
+// loadQueue reads the queue saved by an earlier run; a missing file is an
+// empty queue
+func loadQueue() ([]queue.Item, error) {
+	data, err := os.ReadFile(queuePath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue file: %w", err)
+	}
+
+	var items []queue.Item
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse queue file: %w", err)
+	}
+	return items, nil
+}
+
+// persistQueue restores the queue saved by the last run into q and saves q
+// from then on, every interval at most
+func persistQueue(q *queue.Queue, interval time.Duration, verbose bool) {
+	items, err := loadQueue()
+	if err != nil && verbose {
+		log.Printf("queue: %v", err)
+	}
+	for _, item := range items {
+		q.Restore(item)
+	}
+
+	q.PersistInterval(interval)
+	q.Persist(func(items []queue.Item) {
+		if err := saveQueue(items); err != nil && verbose {
+			log.Printf("queue: %v", err)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"rcloneb/rclone"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// quotaSummaryMsg carries the quota of one remote in the quota summary
+type quotaSummaryMsg struct {
+	remote string
+	info   rclone.QuotaInfo
+	err    error
+}
+
+// openQuotaSummary shows the quota summary and starts reading the quota of
+// every one of remotes
+func (m *Model) openQuotaSummary(remotes []string) tea.Cmd {
+	m.state = StateQuotaSummary
+	m.summaryRemotes = remotes
+	m.summaryQuotas = make(map[string]rclone.QuotaInfo)
+	m.summaryErrs = make(map[string]error)
+	m.loading = true
+	cmds := []tea.Cmd{m.spinner.Tick}
+	for _, remote := range remotes {
+		remote := remote
+		cmds = append(cmds, func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			info, err := rclone.About(ctx, remote)
+			return quotaSummaryMsg{remote: remote, info: info, err: err}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// updateQuotaSummary handles input in the quota summary
+func (m Model) updateQuotaSummary(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Refresh):
+		if !m.loading {
+			return m, m.openQuotaSummary(m.summaryRemotes)
+		}
+	case key.Matches(msg, m.keys.Escape), key.Matches(msg, m.keys.Back), msg.String() == "q":
+		m.state = StateRemoteSelect
+		m.loading = false
+	}
+	return m, nil
+}
+
+// quotaSummaryView renders the quota of each marked remote and their total
+func (m Model) quotaSummaryView() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Quotas: %d remotes", len(m.summaryRemotes))))
+	b.WriteString("\n\n")
+
+	width := 12
+	for _, remote := range m.summaryRemotes {
+		width = max(width, len(remote))
+	}
+	b.WriteString(headerStyle.Render(fmt.Sprintf(" %-*s %10s %10s %10s", width, "Remote", "Used", "Total", "Free")))
+	b.WriteString("\n")
+
+	var used, total, free int64
+	for _, remote := range m.summaryRemotes {
+		line := fmt.Sprintf(" %-*s", width, remote)
+		if err, ok := m.summaryErrs[remote]; ok {
+			b.WriteString(line + " " + errorStyle.Render(err.Error()))
+			b.WriteString("\n")
+			continue
+		}
+		q, ok := m.summaryQuotas[remote]
+		if !ok {
+			b.WriteString(line + " " + bannerStyle.Render("reading..."))
+			b.WriteString("\n")
+			continue
+		}
+
+		line += fmt.Sprintf(" %10s", rclone.FormatSize(q.Used))
+		if q.Total > 0 {
+			line += fmt.Sprintf(" %10s %10s ", rclone.FormatSize(q.Total), rclone.FormatSize(q.Free))
+			barStyle := normalStyle
+			if q.UsedPercent > 90 {
+				barStyle = errorStyle
+			}
+			line += barStyle.Render(quotaBar(q.UsedPercent))
+			used += q.Used
+			total += q.Total
+			free += q.Free
+		} else {
+			line += fmt.Sprintf(" %10s %10s", "-", "-")
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	if total > 0 {
+		b.WriteString("\n")
+		b.WriteString(cursorStyle.Render(fmt.Sprintf(" %-*s %10s %10s %10s", width, "Total",
+			rclone.FormatSize(used), rclone.FormatSize(total), rclone.FormatSize(free))))
+		b.WriteString("\n")
+	}
+
+	if m.loading {
+		b.WriteString("\n")
+		b.WriteString(m.spinner.View())
+		b.WriteString(" Reading quotas...\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("r: read again • esc: back"))
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"time"
+
+	"rcloneb/rclone"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// rcFlagSetMsg is sent when a flag of the running transfer has been changed
+type rcFlagSetMsg struct {
+	flag  string
+	value string
+	err   error
+}
+
+// rcFlagPlaceholders are example values for the flags adjustable from the transfer view
+var rcFlagPlaceholders = map[string]string{
+	"bwlimit":   "10M, or off",
+	"transfers": "4",
+	"checkers":  "8",
+}
+
+// openRCFlag prompts for a new value of a flag of the running transfer.
+// Flags can only be changed through rclone's remote control API.
+func (m *Model) openRCFlag(flag string) tea.Cmd {
+	if !m.config.UseRC {
+		return m.showFlash("Changing "+flag+" during transfers needs remote control (--rc)", 3*time.Second)
+	}
+	m.rcFlag = flag
+	m.rcFlagInput.Prompt = flag + ": "
+	m.rcFlagInput.Placeholder = rcFlagPlaceholders[flag]
+	m.rcFlagInput.SetValue("")
+	m.rcFlagInput.Focus()
+	return textinput.Blink
+}
+
+// updateRCFlag handles the flag value prompt in the transfer view
+func (m Model) updateRCFlag(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.rcFlag = ""
+		m.rcFlagInput.Blur()
+		return m, nil
+	case "enter":
+		flag, value := m.rcFlag, m.rcFlagInput.Value()
+		m.rcFlag = ""
+		m.rcFlagInput.Blur()
+		if value == "" {
+			return m, nil
+		}
+		rcAddr := m.config.RCAddr
+		return m, func() tea.Msg {
+			return rcFlagSetMsg{flag: flag, value: value, err: rclone.SetFlag(rcAddr, flag, value)}
+		}
+	}
+	var cmd tea.Cmd
+	m.rcFlagInput, cmd = m.rcFlagInput.Update(msg)
+	return m, cmd
+}
@@ -0,0 +1,42 @@
+package rclone
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// QuotaInfo is how much of a remote's storage is in use
+type QuotaInfo struct {
+	Remote      string
+	Total       int64
+	Used        int64
+	Free        int64
+	UsedPercent float64 // Zero when the remote does not report a total
+}
+
+// About returns the storage quota of a remote from rclone about. Backends
+// without quotas, such as most sftp servers, return an error.
+func About(ctx context.Context, remote string) (QuotaInfo, error) {
+	cmd := configCommand(ctx, "about", "--json", remote+":")
+	output, err := cmd.Output()
+	if err != nil {
+		return QuotaInfo{}, fmt.Errorf("failed to get quota of %s: %w", remote, err)
+	}
+
+	// Fields the backend cannot report are left out
+	var resp struct {
+		Total int64 `json:"total"`
+		Used  int64 `json:"used"`
+		Free  int64 `json:"free"`
+	}
+	if err := json.Unmarshal(output, &resp); err != nil {
+		return QuotaInfo{}, fmt.Errorf("failed to parse quota of %s: %w", remote, err)
+	}
+
+	info := QuotaInfo{Remote: remote, Total: resp.Total, Used: resp.Used, Free: resp.Free}
+	if info.Total > 0 {
+		info.UsedPercent = float64(info.Used) / float64(info.Total) * 100
+	}
+	return info, nil
+}
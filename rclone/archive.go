@@ -0,0 +1,124 @@
+package rclone
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExtractToLocal downloads the zip archive at remote:zipPath and unpacks it into localDir
+func ExtractToLocal(ctx context.Context, remote, zipPath, localDir string) error {
+	return ExtractToLocalWithProgress(ctx, remote, zipPath, localDir, 0, nil)
+}
+
+// ExtractToLocalWithProgress is ExtractToLocal that reports bytes downloaded so far
+// against size through progress. The archive is buffered in a temporary file because
+// zip needs random access to read its central directory.
+func ExtractToLocalWithProgress(ctx context.Context, remote, zipPath, localDir string, size int64, progress func(done, total int64)) error {
+	remotePath := remote + ":" + zipPath
+
+	tmp, err := os.CreateTemp("", "rcloneb-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	cmd := configCommand(ctx, "cat", remotePath)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start rclone: %w", err)
+	}
+
+	var src io.Reader = stdout
+	if progress != nil {
+		src = &progressReader{r: stdout, total: size, progress: progress}
+	}
+	n, err := io.Copy(tmp, src)
+	if err != nil {
+		// rclone would block writing to the abandoned pipe
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return fmt.Errorf("failed to buffer %s: %w", remotePath, err)
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", remotePath, err)
+	}
+
+	zr, err := zip.NewReader(tmp, n)
+	if err != nil {
+		return fmt.Errorf("failed to open archive %s: %w", remotePath, err)
+	}
+	for _, f := range zr.File {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := extractZipFile(f, localDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractZipFile writes a single archive entry below localDir
+func extractZipFile(f *zip.File, localDir string) error {
+	// Refuse entries that would escape localDir ("zip slip")
+	dst := filepath.Join(localDir, filepath.FromSlash(f.Name))
+	if dst != filepath.Clean(localDir) && !strings.HasPrefix(dst, filepath.Clean(localDir)+string(filepath.Separator)) {
+		return fmt.Errorf("archive entry %s escapes the destination", f.Name)
+	}
+
+	if f.FileInfo().IsDir() {
+		if err := os.MkdirAll(dst, 0o755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dst, err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(dst), err)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to read %s from archive: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, f.Mode().Perm()|0o200)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to extract %s: %w", f.Name, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to extract %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// progressReader reports the running byte count of everything read through it
+type progressReader struct {
+	r        io.Reader
+	done     int64
+	total    int64
+	progress func(done, total int64)
+}
+
+// Read implements io.Reader
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.done += int64(n)
+		p.progress(p.done, p.total)
+	}
+	return n, err
+}
@@ -0,0 +1,62 @@
+package rclone
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// BackendOption is a configuration option of a backend
+type BackendOption struct {
+	Name       string `json:"Name"`
+	Help       string `json:"Help"`
+	Required   bool   `json:"Required"`
+	Advanced   bool   `json:"Advanced"`
+	IsPassword bool   `json:"IsPassword"`
+}
+
+// BackendInfo describes a backend type that remotes can be created with
+type BackendInfo struct {
+	Name        string          `json:"Name"`
+	Description string          `json:"Description"`
+	Prefix      string          `json:"Prefix"`
+	Options     []BackendOption `json:"Options"`
+	Hide        bool            `json:"Hide"`
+}
+
+// ListBackends returns the backend types known to rclone, sorted by name.
+// "rclone config providers" prints JSON, so no output flag is needed.
+func ListBackends() ([]BackendInfo, error) {
+	cmd := configCommand(context.Background(), "config", "providers")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backends: %w", err)
+	}
+
+	var all []BackendInfo
+	if err := json.Unmarshal(output, &all); err != nil {
+		return nil, fmt.Errorf("failed to parse backends: %w", err)
+	}
+
+	// Hidden backends are internal and cannot be configured by users
+	backends := all[:0]
+	for _, b := range all {
+		if !b.Hide {
+			backends = append(backends, b)
+		}
+	}
+	sort.Slice(backends, func(i, j int) bool { return backends[i].Name < backends[j].Name })
+	return backends, nil
+}
+
+// RequiredOptions returns the options that must be set to create a remote of this type
+func (b BackendInfo) RequiredOptions() []BackendOption {
+	var required []BackendOption
+	for _, o := range b.Options {
+		if o.Required && !o.Advanced {
+			required = append(required, o)
+		}
+	}
+	return required
+}
@@ -0,0 +1,167 @@
+package rclone
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ListCache is an in-memory cache of directory listings with per-entry expiry
+type ListCache struct {
+	entries map[string]listCacheEntry
+	hashes  map[string]hashCacheEntry // Keyed by remote:path of each file
+	trees   map[string]listCacheEntry // Keyed by remote:path:depth
+	mu      sync.RWMutex
+}
+
+// DefaultTreeTTL is how long directory trees are cached; directories change
+// far less often than the files in them
+const DefaultTreeTTL = 10 * time.Minute
+
+// listCacheEntry holds a cached listing and when it expires
+type listCacheEntry struct {
+	items   []FileItem
+	hashed  bool // The listing was made with FetchChecksums
+	stored  time.Time
+	expires time.Time
+}
+
+// hashCacheEntry holds the hashes of one file from a cached listing
+type hashCacheEntry struct {
+	hashes  map[string]string
+	expires time.Time
+}
+
+// NewListCache creates an empty listing cache
+func NewListCache() *ListCache {
+	return &ListCache{
+		entries: make(map[string]listCacheEntry),
+		hashes:  make(map[string]hashCacheEntry),
+		trees:   make(map[string]listCacheEntry),
+	}
+}
+
+// Get returns a copy of the cached listing for remote:path if it has not expired
+func (c *ListCache) Get(remote, path string) ([]FileItem, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[remote+":"+path]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+
+	items := make([]FileItem, len(entry.items))
+	copy(items, entry.items)
+	return items, true
+}
+
+// Set stores a listing for remote:path that stays valid for ttl
+func (c *ListCache) Set(remote, path string, items []FileItem, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stored := make([]FileItem, len(items))
+	copy(stored, items)
+	expires := time.Now().Add(ttl)
+	hashed := false
+	for _, item := range stored {
+		if len(item.Hashes) > 0 {
+			hashed = true
+			c.hashes[remote+":"+item.Path] = hashCacheEntry{hashes: item.Hashes, expires: expires}
+		}
+	}
+	c.entries[remote+":"+path] = listCacheEntry{
+		items:   stored,
+		hashed:  hashed,
+		stored:  time.Now(),
+		expires: expires,
+	}
+}
+
+// Hashes returns the hashes of the file at remote:filePath from an unexpired
+// listing made with FetchChecksums
+func (c *ListCache) Hashes(remote, filePath string) (map[string]string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.hashes[remote+":"+filePath]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.hashes, true
+}
+
+// Age returns how long ago the unexpired listing for remote:path was cached
+func (c *ListCache) Age(remote, path string) (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[remote+":"+path]
+	if !ok || time.Now().After(entry.expires) {
+		return 0, false
+	}
+	return time.Since(entry.stored), true
+}
+
+// ListFilesCached lists remote:path, serving the listing from the cache when
+// possible and caching fresh listings for ttl. forceRefresh bypasses the cache
+// and repopulates it, as does asking for checksums the cached listing lacks.
+// Only the checksum and fast-list options of opts apply. Parse errors are
+// only returned for fresh listings.
+func (c *ListCache) ListFilesCached(remote, path string, opts ListOptions, forceRefresh bool, ttl time.Duration) ([]FileItem, []error, error) {
+	checksums := opts.FetchChecksums
+	if !forceRefresh && (!checksums || c.hashed(remote, path)) {
+		if items, ok := c.Get(remote, path); ok {
+			return items, nil, nil
+		}
+	}
+
+	items, parseErrs, err := listFilesOpts(remote, path, ListOptions{FetchChecksums: checksums, FastList: opts.FastList})
+	if err != nil {
+		return nil, parseErrs, err
+	}
+	c.Set(remote, path, items, ttl)
+	return items, parseErrs, nil
+}
+
+// hashed reports whether the cached listing of remote:path has checksums
+func (c *ListCache) hashed(remote, path string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.entries[remote+":"+path].hashed
+}
+
+// ListDirTreeCached is ListDirTree served from a cache kept apart from file
+// listings, so refreshing a directory does not re-walk its tree. Fresh trees
+// are cached for ttl.
+func (c *ListCache) ListDirTreeCached(ctx context.Context, remote, path string, depth int, ttl time.Duration) ([]FileItem, error) {
+	key := fmt.Sprintf("%s:%s:%d", remote, path, depth)
+
+	c.mu.RLock()
+	entry, ok := c.trees[key]
+	c.mu.RUnlock()
+	if ok && time.Now().Before(entry.expires) {
+		items := make([]FileItem, len(entry.items))
+		copy(items, entry.items)
+		return items, nil
+	}
+
+	items, err := ListDirTree(ctx, remote, path, depth)
+	if err != nil {
+		return nil, err
+	}
+	if ttl > 0 {
+		stored := make([]FileItem, len(items))
+		copy(stored, items)
+		c.mu.Lock()
+		c.trees[key] = listCacheEntry{items: stored, stored: time.Now(), expires: time.Now().Add(ttl)}
+		c.mu.Unlock()
+	}
+	return items, nil
+}
@@ -0,0 +1,75 @@
+package rclone
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// configPassEnv holds the password of an encrypted rclone config
+const configPassEnv = "RCLONE_CONFIG_PASS"
+
+// ConfigPasswordSet reports whether the password of an encrypted rclone
+// config is available from RCLONE_CONFIG_PASS
+func ConfigPasswordSet() bool {
+	return os.Getenv(configPassEnv) != ""
+}
+
+// rcloneConfigKey is the context key of the config file set by
+// WithRcloneConfig
+type rcloneConfigKey struct{}
+
+// WithRcloneConfig returns a copy of ctx under which rclone commands use the
+// config file at configPath (--config) instead of the default one. An empty
+// configPath keeps the default.
+func WithRcloneConfig(ctx context.Context, configPath string) context.Context {
+	return context.WithValue(ctx, rcloneConfigKey{}, configPath)
+}
+
+// rcloneConfig returns the config file set on ctx by WithRcloneConfig
+func rcloneConfig(ctx context.Context) string {
+	configPath, _ := ctx.Value(rcloneConfigKey{}).(string)
+	return configPath
+}
+
+// configCommand returns an rclone command. Every rclone command reads the
+// config, and there is no terminal for rclone to prompt on under the TUI, so
+// when RCLONE_CONFIG_PASS is set it is passed on and prompting is turned off.
+// The config file set on ctx by WithRcloneConfig is passed as --config. All
+// rclone subprocesses should be started with it.
+func configCommand(ctx context.Context, args ...string) *exec.Cmd {
+	if configPath := rcloneConfig(ctx); configPath != "" {
+		args = append(args, "--config", configPath)
+	}
+	pass := os.Getenv(configPassEnv)
+	if pass != "" {
+		args = append(args, "--ask-password=false")
+	}
+	cmd := exec.CommandContext(ctx, "rclone", args...)
+	if pass != "" {
+		cmd.Env = append(os.Environ(), configPassEnv+"="+pass)
+	}
+	return cmd
+}
+
+// ConfigEncrypted reports whether rclone's config file is encrypted, so
+// rclone needs a password to read it
+func ConfigEncrypted() (bool, error) {
+	// "rclone config file" names the file without decrypting it
+	output, err := configCommand(context.Background(), "config", "file").Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to find config file: %w", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	path := strings.TrimSpace(lines[len(lines)-1])
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return strings.Contains(string(data), "RCLONE_ENCRYPT_V0:"), nil
+}
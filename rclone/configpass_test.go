@@ -0,0 +1,59 @@
+package rclone
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestConfigCommandConfig(t *testing.T) {
+	cmd := configCommand(context.Background(), "lsjson", "remote:")
+	if slices.Contains(cmd.Args, "--config") {
+		t.Errorf("args = %v, want no --config by default", cmd.Args)
+	}
+
+	ctx := WithRcloneConfig(context.Background(), "/other/rclone.conf")
+	cmd = configCommand(ctx, "lsjson", "remote:")
+	i := slices.Index(cmd.Args, "--config")
+	if i < 0 || i+1 >= len(cmd.Args) || cmd.Args[i+1] != "/other/rclone.conf" {
+		t.Errorf("args = %v, want --config /other/rclone.conf", cmd.Args)
+	}
+}
+
+func TestCopyFileRcloneConfig(t *testing.T) {
+	requireRclone(t)
+
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "file.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// The remote is only in the other config, not the default one
+	dir := t.TempDir()
+	other := filepath.Join(dir, "other.conf")
+	if err := os.WriteFile(other, []byte("[other]\ntype = alias\nremote = "+src+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, "empty.conf")
+	if err := os.WriteFile(empty, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("RCLONE_CONFIG", empty)
+
+	m := NewTransferManager()
+	m.Add("default", "other:file.txt", t.TempDir(), 5)
+	if err := CopyFile(context.Background(), m, "default", "other", "file.txt", t.TempDir()); err == nil {
+		t.Error("CopyFile from a remote missing from the default config succeeded")
+	}
+
+	dst := t.TempDir()
+	m.Add("other", "other:file.txt", dst, 5)
+	ctx := WithRcloneConfig(context.Background(), other)
+	if err := CopyFile(ctx, m, "other", "other", "file.txt", dst); err != nil {
+		t.Fatalf("CopyFile with the other config: %v", err)
+	}
+	if got, err := os.ReadFile(filepath.Join(dst, "file.txt")); err != nil || string(got) != "hello" {
+		t.Errorf("copied file = %q, %v, want %q", got, err, "hello")
+	}
+}
@@ -0,0 +1,121 @@
+package rclone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DiagResult is the outcome of one DiagnoseRemote test
+type DiagResult struct {
+	Test   string
+	Status string // DiagPass or DiagFail
+	Detail string
+}
+
+// DiagnoseRemote test statuses
+const (
+	DiagPass = "pass"
+	DiagFail = "fail"
+)
+
+// diagTimeout bounds each DiagnoseRemote test
+const diagTimeout = 10 * time.Second
+
+// diagTest is a DiagnoseRemote test, returning a detail line on success
+type diagTest struct {
+	name string
+	run  func(ctx context.Context, remote string) (string, error)
+}
+
+// diagTests are the tests DiagnoseRemote runs, in reporting order
+var diagTests = []diagTest{
+	{"listremotes", func(ctx context.Context, remote string) (string, error) {
+		output, err := configCommand(ctx, "listremotes").Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to list remotes: %w", err)
+		}
+		for _, line := range strings.Fields(string(output)) {
+			if strings.TrimSuffix(line, ":") == remote {
+				return "remote is configured", nil
+			}
+		}
+		return "", fmt.Errorf("remote %s is not in the rclone config", remote)
+	}},
+	{"ls", func(ctx context.Context, remote string) (string, error) {
+		output, err := configCommand(ctx, "lsf", "--max-depth", "1", remote+":").Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to list %s: %w", remote+":", err)
+		}
+		return fmt.Sprintf("%d entries at the root", strings.Count(string(output), "\n")), nil
+	}},
+	{"size", func(ctx context.Context, remote string) (string, error) {
+		count, bytes, err := Size(ctx, remote+":")
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d files, %s", count, FormatSize(bytes)), nil
+	}},
+	{"about", func(ctx context.Context, remote string) (string, error) {
+		info, err := About(ctx, remote)
+		if err != nil {
+			return "", err
+		}
+		if info.Total > 0 {
+			return fmt.Sprintf("%s used of %s", FormatSize(info.Used), FormatSize(info.Total)), nil
+		}
+		return fmt.Sprintf("%s used", FormatSize(info.Used)), nil
+	}},
+}
+
+// DiagnoseRemote checks that remote can be found in the config, listed, sized
+// and asked for its quota. The tests run in parallel, each limited to ten
+// seconds, and a failed test is reported in its result rather than as an
+// error; the error is only set when ctx ends first.
+func DiagnoseRemote(ctx context.Context, remote string) ([]DiagResult, error) {
+	results := make([]DiagResult, len(diagTests))
+
+	var wg sync.WaitGroup
+	for i, test := range diagTests {
+		wg.Add(1)
+		go func(i int, test diagTest) {
+			defer wg.Done()
+			tctx, cancel := context.WithTimeout(ctx, diagTimeout)
+			defer cancel()
+
+			result := DiagResult{Test: test.name, Status: DiagPass}
+			detail, err := test.run(tctx, remote)
+			if err != nil {
+				result.Status = DiagFail
+				detail = withStderr(err)
+				if tctx.Err() == context.DeadlineExceeded {
+					detail = fmt.Sprintf("timed out after %s", diagTimeout)
+				}
+			}
+			result.Detail = detail
+			results[i] = result
+		}(i, test)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+// withStderr adds the last line rclone wrote to stderr, if any, to err
+func withStderr(err error) string {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		lines := strings.Split(strings.TrimSpace(string(exitErr.Stderr)), "\n")
+		if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+			return err.Error() + ": " + last
+		}
+	}
+	return err.Error()
+}
@@ -0,0 +1,14 @@
+package rclone
+
+import (
+	"os"
+	"strings"
+)
+
+// GetEnv returns the value of the environment variable rclone reads for a flag,
+// e.g. bwlimit is read from RCLONE_BWLIMIT
+func GetEnv(flagName string) (string, bool) {
+	name := strings.TrimLeft(flagName, "-")
+	name = "RCLONE_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	return os.LookupEnv(name)
+}
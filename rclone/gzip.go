@@ -0,0 +1,56 @@
+package rclone
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+)
+
+// gzipSampleSize is how much of a file EstimateGzipRatio compresses
+const gzipSampleSize = 1 << 20
+
+// CopyFromStdinGzipped is CopyFromStdin with data gzip-compressed on the way
+func CopyFromStdinGzipped(ctx context.Context, data io.Reader, remote, path string) error {
+	pr, pw := io.Pipe()
+	go func() {
+		zw := gzip.NewWriter(pw)
+		_, err := io.Copy(zw, data)
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+		pw.CloseWithError(err)
+	}()
+
+	err := CopyFromStdin(ctx, pr, remote, path)
+	// Unblock the compressor if rclone stopped reading early
+	pr.Close()
+	return err
+}
+
+// EstimateGzipRatio compresses up to the first 1 MiB read from r and returns
+// the compressed size as a fraction of the original, or 1 for empty input
+func EstimateGzipRatio(r io.Reader) (float64, error) {
+	var counter countingWriter
+	zw := gzip.NewWriter(&counter)
+	n, err := io.Copy(zw, io.LimitReader(r, gzipSampleSize))
+	if err != nil {
+		return 0, fmt.Errorf("failed to sample for compression: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return 0, fmt.Errorf("failed to sample for compression: %w", err)
+	}
+	if n == 0 {
+		return 1, nil
+	}
+	return float64(counter) / float64(n), nil
+}
+
+// countingWriter discards what is written to it, counting the bytes
+type countingWriter int64
+
+// Write implements io.Writer
+func (c *countingWriter) Write(b []byte) (int, error) {
+	*c += countingWriter(len(b))
+	return len(b), nil
+}
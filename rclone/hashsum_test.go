@@ -0,0 +1,77 @@
+package rclone
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeHashFiles writes files under a new temporary directory and returns it
+func writeHashFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestHashSums(t *testing.T) {
+	requireRclone(t)
+
+	files := map[string]string{
+		"a.txt":               "hello",
+		"name with spaces.md": "spaces are kept",
+		"sub/b.bin":           "\x00\x01\x02",
+		"empty":               "",
+	}
+	dir := writeHashFiles(t, files)
+
+	tests := []struct {
+		name string
+		sum  func(context.Context, string, string) (map[string]string, error)
+		hash func([]byte) string
+	}{
+		{"MD5Sum", MD5Sum, func(b []byte) string { s := md5.Sum(b); return hex.EncodeToString(s[:]) }},
+		{"SHA1Sum", SHA1Sum, func(b []byte) string { s := sha1.Sum(b); return hex.EncodeToString(s[:]) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := make(map[string]string)
+			for name, content := range files {
+				want[name] = tt.hash([]byte(content))
+			}
+			got, err := tt.sum(context.Background(), ":local", dir)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			if !maps.Equal(got, want) {
+				t.Errorf("%s = %v, want %v", tt.name, got, want)
+			}
+
+			// A single file is keyed by its own name
+			got, err = tt.sum(context.Background(), ":local", filepath.Join(dir, "a.txt"))
+			if err != nil {
+				t.Fatalf("%s of one file: %v", tt.name, err)
+			}
+			if want := map[string]string{"a.txt": want["a.txt"]}; !maps.Equal(got, want) {
+				t.Errorf("%s of one file = %v, want %v", tt.name, got, want)
+			}
+
+			if _, err := tt.sum(context.Background(), ":local", filepath.Join(dir, "missing")); err == nil {
+				t.Errorf("%s of a missing directory succeeded", tt.name)
+			}
+		})
+	}
+}
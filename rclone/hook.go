@@ -0,0 +1,24 @@
+package rclone
+
+import "time"
+
+// TransferHook is notified of transfer events as the TransferManager records
+// them. Hooks are called synchronously from the goroutine running the
+// transfer, so they should return quickly.
+type TransferHook interface {
+	OnStart(id, src, dst string)
+	OnProgress(id string, pct float64, bytesCopied, bytesTotal int64)
+	OnComplete(id string, duration time.Duration)
+	OnFail(id string, err error)
+}
+
+// WithHook registers hook for every later transfer event and returns the
+// manager so it can be chained onto NewTransferManager
+func (m *TransferManager) WithHook(hook TransferHook) *TransferManager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Copy so event calls holding the old slice are unaffected
+	m.hooks = append(append([]TransferHook(nil), m.hooks...), hook)
+	return m
+}
@@ -0,0 +1,143 @@
+package rclone
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingHook records the transfer events it is given, in order
+type recordingHook struct {
+	mu     sync.Mutex
+	events []string
+	err    error
+}
+
+func (h *recordingHook) record(event string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// Collapse progress runs, whose length depends on timing
+	if event == "progress" && len(h.events) > 0 && h.events[len(h.events)-1] == event {
+		return
+	}
+	h.events = append(h.events, event)
+}
+
+func (h *recordingHook) OnStart(id, src, dst string) { h.record("start") }
+
+func (h *recordingHook) OnProgress(id string, pct float64, bytesCopied, bytesTotal int64) {
+	h.record("progress")
+}
+
+func (h *recordingHook) OnComplete(id string, duration time.Duration) { h.record("complete") }
+
+func (h *recordingHook) OnFail(id string, err error) {
+	h.mu.Lock()
+	h.err = err
+	h.mu.Unlock()
+	h.record("fail")
+}
+
+func (h *recordingHook) Events() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return slices.Clone(h.events)
+}
+
+func TestTransferHookOrder(t *testing.T) {
+	hook := &recordingHook{}
+	m := NewTransferManager().WithHook(hook)
+
+	m.Add("ok", "remote:a", "/tmp", 100)
+	m.Start("ok")
+	m.UpdateProgress("ok", 50, 50, 100, "")
+	m.UpdateProgress("ok", 100, 100, 100, "")
+	m.Complete("ok")
+
+	wantErr := errors.New("boom")
+	m.Add("bad", "remote:b", "/tmp", 100)
+	m.Start("bad")
+	m.Fail("bad", wantErr)
+
+	// Events for unknown transfers are not reported
+	m.Start("missing")
+	m.Complete("missing")
+
+	want := []string{"start", "progress", "complete", "start", "fail"}
+	if got := hook.Events(); !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	if !errors.Is(hook.err, wantErr) {
+		t.Errorf("OnFail error = %v, want %v", hook.err, wantErr)
+	}
+}
+
+func TestTransferHookCopyFile(t *testing.T) {
+	requireRclone(t)
+
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "file.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dst := t.TempDir()
+
+	t.Run("complete", func(t *testing.T) {
+		hook := &recordingHook{}
+		m := NewTransferManager().WithHook(hook)
+		m.Add("t", ":local:"+src+"/file.txt", dst, 5)
+
+		if err := CopyFile(context.Background(), m, "t", ":local", src+"/file.txt", dst); err != nil {
+			t.Fatalf("CopyFile: %v", err)
+		}
+		events := hook.Events()
+		events = slices.DeleteFunc(events, func(e string) bool { return e == "progress" })
+		if want := []string{"start", "complete"}; !slices.Equal(events, want) {
+			t.Errorf("events = %v, want %v with optional progress between", hook.Events(), want)
+		}
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		hook := &recordingHook{}
+		m := NewTransferManager().WithHook(hook)
+		m.Add("t", ":local:"+src+"/missing.txt", dst, 0)
+
+		if err := CopyFile(context.Background(), m, "t", ":local", src+"/missing.txt", dst); err == nil {
+			t.Fatal("CopyFile of a missing file succeeded")
+		}
+		events := hook.Events()
+		if len(events) < 2 || events[0] != "start" || events[len(events)-1] != "fail" {
+			t.Errorf("events = %v, want start first and fail last", events)
+		}
+		if hook.err == nil {
+			t.Error("OnFail was given a nil error")
+		}
+	})
+}
+
+func TestTransferCurrentStatus(t *testing.T) {
+	m := NewTransferManager()
+	m.Add("t", "remote:a", "/tmp", 100)
+	tr := m.Get("t")
+
+	// Run with -race: the status is read while the transfer is updated
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		m.Start("t")
+		m.UpdateProgress("t", 50, 50, 100, "")
+		m.Complete("t")
+	}()
+	for tr.CurrentStatus() != StatusCompleted {
+		_ = tr.CopiedBytes()
+	}
+	wg.Wait()
+	if got := tr.CopiedBytes(); got != 50 {
+		t.Errorf("CopiedBytes = %d, want 50", got)
+	}
+}
@@ -0,0 +1,98 @@
+package rclone
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// jsonLogVersion is the first rclone release with --use-json-log
+const jsonLogVersion = "1.57"
+
+// Version returns the version of the installed rclone, such as "1.65.0"
+func Version() (string, error) {
+	output, err := configCommand(context.Background(), "version").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get rclone version: %w", err)
+	}
+
+	// The first line is "rclone v1.65.0"
+	line, _, _ := strings.Cut(string(output), "\n")
+	fields := strings.Fields(line)
+	if len(fields) < 2 || fields[0] != "rclone" {
+		return "", fmt.Errorf("failed to parse rclone version %q", line)
+	}
+	return strings.TrimPrefix(fields[1], "v"), nil
+}
+
+// jsonLogSupported reports whether the installed rclone can log as JSON,
+// checking the version once per process
+var jsonLogSupported = sync.OnceValue(func() bool {
+	v, err := Version()
+	return err == nil && !versionLess(v, jsonLogVersion)
+})
+
+// jsonLogEntry is one line of rclone's --use-json-log output
+type jsonLogEntry struct {
+	Level string `json:"level"`
+	Msg   string `json:"msg"`
+	Stats *struct {
+		Bytes      int64    `json:"bytes"`
+		TotalBytes int64    `json:"totalBytes"`
+		Speed      float64  `json:"speed"`
+		Checking   []string `json:"checking"`
+	} `json:"stats"`
+}
+
+// parseJSONLog is parseRcloneOutput for rclone's --use-json-log output, where
+// progress comes from the stats object of each stats entry instead of
+// matching text. Lines that are not JSON are skipped. It returns the last
+// error message seen.
+func parseJSONLog(reader *bufio.Reader, transferID string, mgr *TransferManager) string {
+	var lastError string
+	chunksDone := 0
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		start := strings.IndexByte(line, '{')
+		if start < 0 {
+			continue
+		}
+		var entry jsonLogEntry
+		if err := json.Unmarshal([]byte(line[start:]), &entry); err != nil {
+			continue
+		}
+
+		if entry.Level == "error" || entry.Level == "critical" {
+			lastError = strings.TrimSpace(entry.Msg)
+			mgr.noteError(lastError)
+			if isRateLimit(lastError) {
+				mgr.SetRateLimited(transferID)
+			}
+		}
+
+		// Chunks finish out of order, so count them rather than trusting N
+		if m := chunkRegex.FindStringSubmatch(entry.Msg); m != nil {
+			if total, err := strconv.Atoi(m[2]); err == nil {
+				chunksDone++
+				mgr.UpdateChunks(transferID, chunksDone, total)
+			}
+		}
+
+		if s := entry.Stats; s != nil && len(s.Checking) > 0 {
+			mgr.SetChecking(transferID, s.Checking[len(s.Checking)-1])
+		}
+		if s := entry.Stats; s != nil && s.TotalBytes > 0 {
+			percentage := float64(s.Bytes) / float64(s.TotalBytes) * 100
+			mgr.UpdateProgress(transferID, percentage, s.Bytes, s.TotalBytes, FormatSpeed(s.Speed))
+		}
+	}
+	return lastError
+}
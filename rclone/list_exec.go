@@ -0,0 +1,72 @@
+//go:build !librclone
+
+package rclone
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ListRemotes returns a list of configured rclone remotes
+func ListRemotes() ([]string, error) {
+	cmd := configCommand(context.Background(), "listremotes")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	remotes := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			// Remove trailing colon if present
+			remotes = append(remotes, strings.TrimSuffix(line, ":"))
+		}
+	}
+	return remotes, nil
+}
+
+// ListFiles returns the files and directories at the given remote path.
+// Entries that fail to decode are skipped and reported in the returned
+// slice of per-item errors rather than failing the whole listing.
+func ListFiles(remote, path string) ([]FileItem, []error, error) {
+	return listFilesOpts(remote, path, ListOptions{})
+}
+
+// listFilesOpts is ListFiles with the lsjson flags set by opts; sorting is
+// left to the caller
+func listFilesOpts(remote, path string, opts ListOptions) ([]FileItem, []error, error) {
+	remotePath := remote + ":" + path
+	args := []string{"lsjson"}
+	switch opts.Mode {
+	case FilesOnly:
+		args = append(args, "--files-only")
+	case DirsOnly:
+		args = append(args, "--dirs-only")
+	}
+	if opts.FetchChecksums {
+		args = append(args, "--hash")
+	}
+	if opts.MaxDepth > 1 {
+		args = append(args, "--recursive", "--max-depth", strconv.Itoa(opts.MaxDepth))
+	}
+	if useFastList(remote, opts) {
+		args = append(args, "--fast-list")
+	}
+	ctx := WithRcloneConfig(context.Background(), opts.RcloneConfig)
+	cmd := configCommand(ctx, append(args, remotePath)...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list files at %s: %w", remotePath, err)
+	}
+
+	items, errs := ParseLsjsonOutput(output)
+	if opts.MaxDepth > 1 {
+		setRelativeNames(items)
+	}
+	setFullPaths(items, path)
+	return items, errs, nil
+}
@@ -0,0 +1,14 @@
+//go:build !librclone
+
+package rclone
+
+import "testing"
+
+// listBackend names the ListFiles implementation of this build
+const listBackend = "exec"
+
+// requireListBackend skips tb when ListFiles cannot run, since it execs rclone
+func requireListBackend(tb testing.TB) {
+	tb.Helper()
+	requireRclone(tb)
+}
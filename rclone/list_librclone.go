@@ -0,0 +1,124 @@
+//go:build librclone
+
+// Listing through rclone's Go library instead of a subprocess.
+//
+// The library is not a default dependency, so fetch it before building
+// (v1.65.2 is the last release that builds with go 1.21):
+//
+//	go get github.com/rclone/rclone@v1.65.2
+//	CGO_ENABLED=1 go build -tags librclone .
+//
+// CGO is needed by some rclone backends (and by the C-ABI librclone); the pure
+// Go backends work with CGO_ENABLED=0 as well. rclone reads the same config
+// file as the rclone binary, so no other setup is required.
+
+package rclone
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	_ "github.com/rclone/rclone/backend/all"   // Register every backend
+	_ "github.com/rclone/rclone/fs/operations" // Register operations/* rc calls
+	"github.com/rclone/rclone/librclone/librclone"
+)
+
+// initLibrclone initializes the embedded rclone library on first use
+var initLibrclone = sync.OnceFunc(librclone.Initialize)
+
+// rpc calls an rclone rc method in process and decodes its JSON result into out
+func rpc(method string, in, out any) error {
+	initLibrclone()
+
+	input, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+
+	output, status := librclone.RPC(method, string(input))
+	if status != 200 {
+		var rcErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal([]byte(output), &rcErr) == nil && rcErr.Error != "" {
+			return fmt.Errorf("%s failed: %s", method, rcErr.Error)
+		}
+		return fmt.Errorf("%s failed with status %d", method, status)
+	}
+
+	if err := json.Unmarshal([]byte(output), out); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", method, err)
+	}
+	return nil
+}
+
+// ListRemotes returns a list of configured rclone remotes
+func ListRemotes() ([]string, error) {
+	var resp struct {
+		Remotes []string `json:"remotes"`
+	}
+	if err := rpc("config/listremotes", map[string]any{}, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+	return resp.Remotes, nil
+}
+
+// ListFiles returns the files and directories at the given remote path.
+// Entries that fail to decode are skipped and reported in the returned
+// slice of per-item errors rather than failing the whole listing.
+func ListFiles(remote, path string) ([]FileItem, []error, error) {
+	return listFilesOpts(remote, path, ListOptions{})
+}
+
+// listFilesOpts is ListFiles with the operations/list options set by opts;
+// sorting is left to the caller
+func listFilesOpts(remote, path string, opts ListOptions) ([]FileItem, []error, error) {
+	remotePath := remote + ":" + path
+	if opts.RcloneConfig != "" {
+		// librclone loads one config file for the whole process
+		return nil, nil, fmt.Errorf("failed to list files at %s: a per-listing config file needs the rclone binary", remotePath)
+	}
+
+	// operations/list returns the same objects as lsjson, wrapped in "list"
+	var resp struct {
+		List json.RawMessage `json:"list"`
+	}
+	// Rooting fs at the path, as lsjson does, keeps local paths absolute
+	in := map[string]any{"fs": remotePath, "remote": ""}
+	opt := map[string]any{}
+	switch opts.Mode {
+	case FilesOnly:
+		opt["filesOnly"] = true
+	case DirsOnly:
+		opt["dirsOnly"] = true
+	}
+	if opts.FetchChecksums {
+		opt["showHash"] = true
+	}
+	config := map[string]any{}
+	if opts.MaxDepth > 1 {
+		opt["recurse"] = true
+		config["MaxDepth"] = opts.MaxDepth
+	}
+	if useFastList(remote, opts) {
+		// UseListR is the config behind --fast-list
+		config["UseListR"] = true
+	}
+	if len(config) > 0 {
+		in["_config"] = config
+	}
+	if len(opt) > 0 {
+		in["opt"] = opt
+	}
+	if err := rpc("operations/list", in, &resp); err != nil {
+		return nil, nil, fmt.Errorf("failed to list files at %s: %w", remotePath, err)
+	}
+
+	items, errs := ParseLsjsonOutput(resp.List)
+	if opts.MaxDepth > 1 {
+		setRelativeNames(items)
+	}
+	setFullPaths(items, path)
+	return items, errs, nil
+}
@@ -0,0 +1,11 @@
+//go:build librclone
+
+package rclone
+
+import "testing"
+
+// listBackend names the ListFiles implementation of this build
+const listBackend = "librclone"
+
+// requireListBackend does nothing, since rclone is linked in
+func requireListBackend(tb testing.TB) {}
@@ -0,0 +1,110 @@
+package rclone
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// MountInfo describes a remote mounted with "rclone mount"
+type MountInfo struct {
+	Remote     string // As given to rclone mount, such as "gdrive:photos"
+	MountPoint string
+}
+
+// ListMounts returns the rclone FUSE mounts in the system mount table.
+// rclone has no command to list mounts made outside an rcd daemon, so they
+// are found by their "fuse.rclone" filesystem type. Only Linux exposes the
+// mount table as a file; elsewhere no mounts are reported.
+func ListMounts() ([]MountInfo, error) {
+	f, err := os.Open("/proc/self/mounts")
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mount table: %w", err)
+	}
+	defer f.Close()
+
+	var mounts []MountInfo
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// device mountpoint fstype options dump pass
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[2] != "fuse.rclone" {
+			continue
+		}
+		mounts = append(mounts, MountInfo{
+			Remote:     unescapeMountField(fields[0]),
+			MountPoint: unescapeMountField(fields[1]),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mount table: %w", err)
+	}
+	return mounts, nil
+}
+
+// StaleMounts returns the rclone mounts whose rclone process has gone, which
+// the kernel reports by failing every access to the mount point with
+// ENOTCONN ("transport endpoint is not connected"). Healthy mounts, such as
+// one kept by a systemd service, are left out.
+func StaleMounts() ([]MountInfo, error) {
+	mounts, err := ListMounts()
+	if err != nil {
+		return nil, err
+	}
+	var stale []MountInfo
+	for _, mnt := range mounts {
+		if _, err := os.Stat(mnt.MountPoint); errors.Is(err, syscall.ENOTCONN) {
+			stale = append(stale, mnt)
+		}
+	}
+	return stale, nil
+}
+
+// unescapeMountField decodes the octal escapes such as \040 for a space used
+// in the mount table
+func unescapeMountField(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// Unmount unmounts an rclone mount. rclone has no unmount command, so this
+// uses fusermount on Linux and umount elsewhere.
+func Unmount(mountPoint string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "linux" {
+		cmd = exec.Command("fusermount", "-u", mountPoint)
+		if _, err := exec.LookPath("fusermount"); err != nil {
+			cmd = exec.Command("fusermount3", "-u", mountPoint)
+		}
+	} else {
+		cmd = exec.Command("umount", mountPoint)
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("failed to unmount %s: %w: %s", mountPoint, err, msg)
+		}
+		return fmt.Errorf("failed to unmount %s: %w", mountPoint, err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package rclone
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ncduNode is a directory or file in the disk usage tree
+type ncduNode struct {
+	name     string
+	size     int64
+	isDir    bool
+	children map[string]*ncduNode
+}
+
+// NcduExport writes the disk usage of remote:path to outFile in ncdu's JSON
+// export format, so it can be browsed with "ncdu -f outFile". rclone's own
+// ncdu command is interactive only, so the tree is built from lsjson -R.
+func NcduExport(ctx context.Context, remote, path, outFile string) error {
+	remotePath := remote + ":" + path
+	cmd := configCommand(ctx, "lsjson", "-R", "--no-mimetype", remotePath)
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", remotePath, err)
+	}
+	items, _ := ParseLsjsonOutput(output)
+
+	root := &ncduNode{name: remotePath, isDir: true, children: make(map[string]*ncduNode)}
+	for _, item := range items {
+		// lsjson -R reports paths relative to the listed directory
+		parts := strings.Split(item.Path, "/")
+		dir := root
+		for _, p := range parts[:len(parts)-1] {
+			dir = dir.child(p, true)
+		}
+		n := dir.child(parts[len(parts)-1], item.IsDir)
+		if !item.IsDir {
+			n.size = item.Size
+		}
+	}
+
+	export := []any{
+		1, 0,
+		map[string]any{"progname": "rcloneb", "progver": "1", "timestamp": time.Now().Unix()},
+		root.export(),
+	}
+	data, err := json.Marshal(export)
+	if err != nil {
+		return fmt.Errorf("failed to encode disk usage: %w", err)
+	}
+	if err := os.WriteFile(outFile, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outFile, err)
+	}
+	return nil
+}
+
+// child returns the named child of n, creating it if needed
+func (n *ncduNode) child(name string, isDir bool) *ncduNode {
+	c, ok := n.children[name]
+	if !ok {
+		c = &ncduNode{name: name, isDir: isDir}
+		if isDir {
+			c.children = make(map[string]*ncduNode)
+		}
+		n.children[name] = c
+	}
+	return c
+}
+
+// export converts n to ncdu's representation: a file is an info object and a
+// directory is an array of its info object followed by its children
+func (n *ncduNode) export() any {
+	info := map[string]any{"name": n.name}
+	if !n.isDir {
+		info["asize"] = n.size
+		info["dsize"] = n.size
+		return info
+	}
+
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	dir := []any{info}
+	for _, name := range names {
+		dir = append(dir, n.children[name].export())
+	}
+	return dir
+}
@@ -0,0 +1,89 @@
+package rclone
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// oauthBackends are the backend types that authorize through a web browser
+var oauthBackends = map[string]bool{
+	"box":          true,
+	"drive":        true,
+	"dropbox":      true,
+	"googlephotos": true,
+	"hidrive":      true,
+	"onedrive":     true,
+	"pcloud":       true,
+	"premiumizeme": true,
+	"putio":        true,
+	"yandex":       true,
+	"zoho":         true,
+}
+
+// NeedsOAuth reports whether remotes of the given backend type are authorized
+// in a web browser
+func NeedsOAuth(backend string) bool {
+	return oauthBackends[strings.ToLower(backend)]
+}
+
+// authURLRegex matches the link rclone prints for authorizing in a browser
+var authURLRegex = regexp.MustCompile(`https?://\S+/auth\?state=\S+`)
+
+// StartOAuth runs "rclone config reconnect" for remote and returns the URL
+// the user must open to authorize rclone. rclone waits for the browser to
+// redirect back to it; done receives the result once it exits and is then
+// closed. Cancelling ctx abandons the authorization.
+func StartOAuth(ctx context.Context, remote string) (authURL string, done <-chan error, err error) {
+	// rcloneb opens the browser itself once it knows the URL
+	cmd := configCommand(ctx, "config", "reconnect", remote+":", "--auto-confirm", "--auth-no-open-browser")
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return "", nil, fmt.Errorf("failed to start rclone config reconnect: %w", err)
+	}
+
+	urls := make(chan string, 1)
+	result := make(chan error, 1)
+	go func() {
+		var lastLine string
+		found := false
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !found {
+				if u := authURLRegex.FindString(line); u != "" {
+					urls <- u
+					found = true
+				}
+			}
+			if strings.TrimSpace(line) != "" {
+				lastLine = strings.TrimSpace(line)
+			}
+		}
+		close(urls)
+
+		err := cmd.Wait()
+		if err != nil && lastLine != "" {
+			err = fmt.Errorf("failed to authorize %s: %w: %s", remote, err, lastLine)
+		} else if err != nil {
+			err = fmt.Errorf("failed to authorize %s: %w", remote, err)
+		}
+		result <- err
+		close(result)
+	}()
+
+	u, ok := <-urls
+	if !ok {
+		// rclone exited without asking for authorization
+		if err := <-result; err != nil {
+			return "", nil, err
+		}
+		return "", nil, fmt.Errorf("rclone did not ask to authorize %s in a browser", remote)
+	}
+	return u, result, nil
+}
@@ -0,0 +1,221 @@
+package rclone
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GlobalStats is a snapshot of rclone's core/stats counters
+type GlobalStats struct {
+	BytesTransferred int64
+	Speed            int64 // Bytes per second
+	Errors           int64
+	Checks           int64
+}
+
+// RCTransfer is one file rclone is transferring, as reported by its remote
+// control API
+type RCTransfer struct {
+	Name       string
+	Size       int64
+	Bytes      int64
+	Percentage float64
+	Speed      float64 // Bytes per second
+	ETA        int64   // Seconds remaining; zero when unknown
+}
+
+// GetCurrentTransfers returns the files being transferred by the rclone
+// process whose remote control API listens on rcAddr. rclone reports them in
+// the "transferring" list of core/stats; there is no separate endpoint.
+func GetCurrentTransfers(rcAddr string) ([]RCTransfer, error) {
+	if rcAddr == "" {
+		return nil, fmt.Errorf("no rc address configured")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var resp struct {
+		Transferring []struct {
+			Name       string  `json:"name"`
+			Size       int64   `json:"size"`
+			Bytes      int64   `json:"bytes"`
+			Percentage float64 `json:"percentage"`
+			Speed      float64 `json:"speed"`
+			ETA        *int64  `json:"eta"`
+		} `json:"transferring"`
+	}
+	if err := rcCall(ctx, rcAddr, "core/stats", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	transfers := make([]RCTransfer, 0, len(resp.Transferring))
+	for _, t := range resp.Transferring {
+		rt := RCTransfer{Name: t.Name, Size: t.Size, Bytes: t.Bytes, Percentage: t.Percentage, Speed: t.Speed}
+		if t.ETA != nil {
+			rt.ETA = *t.ETA
+		}
+		transfers = append(transfers, rt)
+	}
+	return transfers, nil
+}
+
+// pollTransfers feeds the progress of the files rclone is transferring into
+// the manager every interval until ctx is cancelled. Files copied in parallel
+// are summed into the one transfer.
+func pollTransfers(ctx context.Context, rcAddr string, interval time.Duration, manager *TransferManager, transferID string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Polls fail until rclone's rc server is up
+		transfers, err := GetCurrentTransfers(rcAddr)
+		if err != nil || len(transfers) == 0 {
+			continue
+		}
+		var bytes, size int64
+		var speed float64
+		for _, t := range transfers {
+			bytes += t.Bytes
+			size += t.Size
+			speed += t.Speed
+		}
+		if size > 0 {
+			manager.UpdateProgress(transferID, float64(bytes)/float64(size)*100, bytes, size, FormatSpeed(speed))
+		}
+	}
+}
+
+// rcCall posts a JSON request to an rclone remote control endpoint and decodes the reply
+func rcCall(ctx context.Context, rcAddr, endpoint string, in, out interface{}) error {
+	body := []byte("{}")
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("failed to encode %s request: %w", endpoint, err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+rcAddr+"/"+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", endpoint, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("rc %s failed: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("rc %s failed: %s", endpoint, resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", endpoint, err)
+	}
+	return nil
+}
+
+// SetFlag changes a flag of the running rclone process whose remote control
+// API listens on rcAddr, such as bwlimit or transfers. bwlimit goes through
+// core/bwlimit so it applies at once; other flags are set with options/set
+// on rclone's main options.
+func SetFlag(rcAddr, flag, value string) error {
+	if rcAddr == "" {
+		return fmt.Errorf("no rc address configured")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	flag = strings.TrimLeft(flag, "-")
+	if flag == "bwlimit" {
+		return rcCall(ctx, rcAddr, "core/bwlimit", map[string]string{"rate": value}, nil)
+	}
+
+	// options/set needs the option's own JSON type
+	var v interface{} = value
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		v = n
+	} else if b, err := strconv.ParseBool(value); err == nil {
+		v = b
+	}
+	in := map[string]map[string]interface{}{"main": {optionName(flag): v}}
+	return rcCall(ctx, rcAddr, "options/set", in, nil)
+}
+
+// optionName converts a flag name such as multi-thread-streams to the name of
+// its main option, MultiThreadStreams
+func optionName(flag string) string {
+	parts := strings.Split(flag, "-")
+	for i, p := range parts {
+		if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// PollStats polls the core/stats endpoint every interval and sends the results on
+// the returned channel until ctx is cancelled. Polls that fail, for example while no
+// rclone process is listening, are skipped.
+func PollStats(ctx context.Context, rcAddr string, interval time.Duration) (<-chan GlobalStats, error) {
+	if rcAddr == "" {
+		return nil, fmt.Errorf("no rc address configured")
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid poll interval: %v", interval)
+	}
+
+	ch := make(chan GlobalStats)
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			var resp struct {
+				Bytes  int64   `json:"bytes"`
+				Speed  float64 `json:"speed"`
+				Errors int64   `json:"errors"`
+				Checks int64   `json:"checks"`
+			}
+			if err := rcCall(ctx, rcAddr, "core/stats", nil, &resp); err != nil {
+				continue
+			}
+
+			stats := GlobalStats{
+				BytesTransferred: resp.Bytes,
+				Speed:            int64(resp.Speed),
+				Errors:           resp.Errors,
+				Checks:           resp.Checks,
+			}
+			select {
+			case ch <- stats:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
@@ -4,9 +4,18 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
+	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -20,6 +29,49 @@ type FileItem struct {
 	Size    int64  `json:"Size"`
 	IsDir   bool   `json:"IsDir"`
 	ModTime string `json:"ModTime"`
+
+	// MimeType is the type rclone reports, such as "image/jpeg" or
+	// "inode/directory"; listings that skip it leave it empty
+	MimeType string `json:"MimeType,omitempty"`
+
+	// Hashes maps hash names such as "md5" to their values; only listings
+	// made with ListOptions.FetchChecksums fill it in
+	Hashes map[string]string `json:"Hashes,omitempty"`
+}
+
+// hashStrength lists rclone's hash types from strongest to weakest
+var hashStrength = []string{
+	"sha512", "sha256", "blake3", "whirlpool", "sha1", "md5",
+	"xxh128", "dropbox", "quickxor", "hidrive", "mailru", "xxh3", "crc32",
+}
+
+// BestHash returns the value of the strongest hash known for the file, or
+// "" if it has none
+func (f FileItem) BestHash() string {
+	_, value := f.bestHash()
+	return value
+}
+
+// BestHashType returns the name of the hash BestHash returns
+func (f FileItem) BestHashType() string {
+	name, _ := f.bestHash()
+	return name
+}
+
+// bestHash returns the name and value of the strongest hash of the file
+func (f FileItem) bestHash() (string, string) {
+	for _, name := range hashStrength {
+		if v := f.Hashes[name]; v != "" {
+			return name, v
+		}
+	}
+	// Hash types added to rclone after this list are better than none
+	for name, v := range f.Hashes {
+		if v != "" {
+			return name, v
+		}
+	}
+	return "", ""
 }
 
 // TransferStatus represents the status of a transfer
@@ -30,28 +82,47 @@ const (
 	StatusInProgress
 	StatusCompleted
 	StatusFailed
+	StatusRateLimited // Paused until the remote's rate limit recovers
 )
 
 // Transfer represents an active file transfer
 type Transfer struct {
-	ID          string
-	Source      string
-	Destination string
-	Status      TransferStatus
-	Progress    float64
-	BytesCopied int64
-	BytesTotal  int64
-	Speed       string
-	StartTime   time.Time
-	EndTime     time.Time
-	Error       error
-	mu          sync.Mutex
+	ID            string
+	Source        string
+	Destination   string
+	Status        TransferStatus
+	Progress      float64
+	BytesCopied   int64
+	BytesTotal    int64
+	Speed         string
+	SpeedHistory  []float64 // Bytes per second, sampled once a second
+	ProgressTitle string    // rclone's last terminal title, such as "ETA: 1m30s"
+	CheckingFile  string    // File rclone last reported comparing
+	RetryCount    int
+	Mirror        bool // Runs rclone sync and may delete local files
+	ChunksDone    int  // Finished streams of a multi-thread download
+	ChunksTotal   int
+	ChecksumFile  string    // MD5 file written next to the download, if any
+	Log           LogBuffer // Retries and other events; guarded by mu
+	StartTime     time.Time
+	EndTime       time.Time
+	Error         error
+	sampledAt     time.Time // When SpeedHistory was last sampled
+	sampledBytes  int64
+	mu            sync.Mutex
 }
 
 // TransferManager manages multiple file transfers
 type TransferManager struct {
 	transfers map[string]*Transfer
+	hooks     []TransferHook
+	allowed   []string // Source prefixes tracked; nil tracks every transfer
 	mu        sync.RWMutex
+
+	// Throttling errors seen in a row, and whether they have switched
+	// transfers to low-concurrency mode
+	throttled      int
+	lowConcurrency bool
 }
 
 // NewTransferManager creates a new transfer manager
@@ -61,8 +132,42 @@ func NewTransferManager() *TransferManager {
 	}
 }
 
-// Add adds a new transfer to the manager
+// NewFilteredTransferManager creates a transfer manager that only tracks
+// transfers from the given remotes, such as "gdrive" or "s3:bucket"; Add
+// silently drops the rest
+func NewFilteredTransferManager(allowedRemotes []string) *TransferManager {
+	m := NewTransferManager()
+	m.allowed = make([]string, 0, len(allowedRemotes))
+	for _, r := range allowedRemotes {
+		if !strings.Contains(r, ":") {
+			// A bare remote name covers the whole remote
+			r += ":"
+		}
+		m.allowed = append(m.allowed, r)
+	}
+	return m
+}
+
+// tracks reports whether transfers from source are tracked
+func (m *TransferManager) tracks(source string) bool {
+	if m.allowed == nil {
+		return true
+	}
+	for _, prefix := range m.allowed {
+		if strings.HasPrefix(source, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add adds a new transfer to the manager, unless the manager filters out
+// its source
 func (m *TransferManager) Add(id, source, destination string, totalBytes int64) {
+	if !m.tracks(source) {
+		return
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -78,13 +183,20 @@ func (m *TransferManager) Add(id, source, destination string, totalBytes int64)
 // Start marks a transfer as in progress
 func (m *TransferManager) Start(id string) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	t, exists := m.transfers[id]
+	hooks := m.hooks
+	m.mu.Unlock()
 
-	if t, exists := m.transfers[id]; exists {
+	if exists {
 		t.mu.Lock()
 		t.Status = StatusInProgress
 		t.StartTime = time.Now()
+		src, dst := t.Source, t.Destination
 		t.mu.Unlock()
+
+		for _, h := range hooks {
+			h.OnStart(id, src, dst)
+		}
 	}
 }
 
@@ -92,6 +204,7 @@ func (m *TransferManager) Start(id string) {
 func (m *TransferManager) UpdateProgress(id string, progress float64, bytesCopied, bytesTotal int64, speed string) {
 	m.mu.RLock()
 	t, exists := m.transfers[id]
+	hooks := m.hooks
 	m.mu.RUnlock()
 
 	if exists {
@@ -102,6 +215,146 @@ func (m *TransferManager) UpdateProgress(id string, progress float64, bytesCopie
 			t.BytesTotal = bytesTotal
 		}
 		t.Speed = speed
+		t.recordSpeed(time.Now())
+		total := t.BytesTotal
+		t.mu.Unlock()
+
+		for _, h := range hooks {
+			h.OnProgress(id, progress, bytesCopied, total)
+		}
+	}
+}
+
+// UpdateTitle records the terminal title rclone last set for a transfer
+func (m *TransferManager) UpdateTitle(id, title string) {
+	m.mu.RLock()
+	t, exists := m.transfers[id]
+	m.mu.RUnlock()
+
+	if exists {
+		t.mu.Lock()
+		t.ProgressTitle = title
+		t.mu.Unlock()
+	}
+}
+
+// Retry records a retry attempt after a transient failure, to be made after
+// delay, and logs it to the transfer's LogBuffer; the transfer stays in progress
+func (m *TransferManager) Retry(id string, attempt int, delay time.Duration, err error) {
+	m.mu.RLock()
+	t, exists := m.transfers[id]
+	m.mu.RUnlock()
+
+	if exists {
+		t.mu.Lock()
+		t.RetryCount = attempt
+		t.Error = err
+		t.Log.Append(fmt.Sprintf("retry %d in %s after: %v", attempt, delay.Round(time.Millisecond), err))
+		t.mu.Unlock()
+	}
+}
+
+// LogLines returns the lines logged for a transfer, oldest first
+func (t *Transfer) LogLines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.Log.Lines()
+}
+
+// CurrentStatus returns the transfer's status
+func (t *Transfer) CurrentStatus() TransferStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.Status
+}
+
+// CopiedBytes returns the bytes the transfer has copied so far
+func (t *Transfer) CopiedBytes() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.BytesCopied
+}
+
+// SetMirror flags a transfer as a mirror that may delete local files
+func (m *TransferManager) SetMirror(id string) {
+	m.mu.RLock()
+	t, exists := m.transfers[id]
+	m.mu.RUnlock()
+
+	if exists {
+		t.mu.Lock()
+		t.Mirror = true
+		t.mu.Unlock()
+	}
+}
+
+// SetRateLimited marks a transfer as held up by the remote's rate limit
+func (m *TransferManager) SetRateLimited(id string) {
+	m.setStatus(id, StatusRateLimited)
+}
+
+// setStatus changes the status of a running transfer
+func (m *TransferManager) setStatus(id string, status TransferStatus) {
+	m.mu.RLock()
+	t, exists := m.transfers[id]
+	m.mu.RUnlock()
+
+	if exists {
+		t.mu.Lock()
+		t.Status = status
+		t.mu.Unlock()
+	}
+}
+
+// SetTotal sets the size of a transfer that was queued without one
+func (m *TransferManager) SetTotal(id string, bytesTotal int64) {
+	m.mu.RLock()
+	t, exists := m.transfers[id]
+	m.mu.RUnlock()
+
+	if exists {
+		t.mu.Lock()
+		t.BytesTotal = bytesTotal
+		t.mu.Unlock()
+	}
+}
+
+// SetChecking records the file rclone is comparing with its destination
+func (m *TransferManager) SetChecking(id, name string) {
+	m.mu.RLock()
+	t, exists := m.transfers[id]
+	m.mu.RUnlock()
+
+	if exists {
+		t.mu.Lock()
+		t.CheckingFile = name
+		t.mu.Unlock()
+	}
+}
+
+// SetChecksumFile records the checksum file written for a finished transfer
+func (m *TransferManager) SetChecksumFile(id, path string) {
+	m.mu.RLock()
+	t, exists := m.transfers[id]
+	m.mu.RUnlock()
+
+	if exists {
+		t.mu.Lock()
+		t.ChecksumFile = path
+		t.mu.Unlock()
+	}
+}
+
+// UpdateChunks records how many chunks of a multi-thread download have finished
+func (m *TransferManager) UpdateChunks(id string, done, total int) {
+	m.mu.RLock()
+	t, exists := m.transfers[id]
+	m.mu.RUnlock()
+
+	if exists {
+		t.mu.Lock()
+		t.ChunksDone = done
+		t.ChunksTotal = total
 		t.mu.Unlock()
 	}
 }
@@ -109,28 +362,42 @@ func (m *TransferManager) UpdateProgress(id string, progress float64, bytesCopie
 // Complete marks a transfer as completed
 func (m *TransferManager) Complete(id string) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	t, exists := m.transfers[id]
+	hooks := m.hooks
+	m.throttled = 0
+	m.mu.Unlock()
 
-	if t, exists := m.transfers[id]; exists {
+	if exists {
 		t.mu.Lock()
 		t.Status = StatusCompleted
 		t.Progress = 100
 		t.EndTime = time.Now()
+		duration := t.EndTime.Sub(t.StartTime)
 		t.mu.Unlock()
+
+		for _, h := range hooks {
+			h.OnComplete(id, duration)
+		}
 	}
 }
 
 // Fail marks a transfer as failed
 func (m *TransferManager) Fail(id string, err error) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	t, exists := m.transfers[id]
+	hooks := m.hooks
+	m.mu.Unlock()
 
-	if t, exists := m.transfers[id]; exists {
+	if exists {
 		t.mu.Lock()
 		t.Status = StatusFailed
 		t.Error = err
 		t.EndTime = time.Now()
 		t.mu.Unlock()
+
+		for _, h := range hooks {
+			h.OnFail(id, err)
+		}
 	}
 }
 
@@ -153,6 +420,20 @@ func (m *TransferManager) GetAll() []*Transfer {
 	return result
 }
 
+// TotalBytesTransferred returns the bytes copied so far across all transfers
+func (m *TransferManager) TotalBytesTransferred() int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var total int64
+	for _, t := range m.transfers {
+		t.mu.Lock()
+		total += t.BytesCopied
+		t.mu.Unlock()
+	}
+	return total
+}
+
 // Stats returns pending, in-progress, completed, and failed counts
 func (m *TransferManager) Stats() (pending, inProgress, completed, failed int) {
 	m.mu.RLock()
@@ -163,7 +444,7 @@ func (m *TransferManager) Stats() (pending, inProgress, completed, failed int) {
 		switch t.Status {
 		case StatusPending:
 			pending++
-		case StatusInProgress:
+		case StatusInProgress, StatusRateLimited:
 			inProgress++
 		case StatusCompleted:
 			completed++
@@ -175,137 +456,1403 @@ func (m *TransferManager) Stats() (pending, inProgress, completed, failed int) {
 	return
 }
 
-// ListRemotes returns a list of configured rclone remotes
-func ListRemotes() ([]string, error) {
-	cmd := exec.Command("rclone", "listremotes")
+// RemoteInfo describes a configured remote and its backend type
+type RemoteInfo struct {
+	Name string
+	Type string
+
+	// RequiresConfigPassword is set when the rclone config holding the
+	// remote is encrypted
+	RequiresConfigPassword bool
+
+	// Disabled is set for remotes with "disable = true" in the config; only
+	// ListRemotesAll reports it
+	Disabled bool
+}
+
+// ListRemotesWithType returns the configured remotes along with their backend type
+func ListRemotesWithType() ([]RemoteInfo, error) {
+	cmd := configCommand(context.Background(), "listremotes", "--long")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list remotes: %w", err)
 	}
+	encrypted, _ := ConfigEncrypted()
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	remotes := make([]string, 0, len(lines))
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line != "" {
-			// Remove trailing colon if present
-			remotes = append(remotes, strings.TrimSuffix(line, ":"))
+	var remotes []RemoteInfo
+	for _, line := range strings.Split(string(output), "\n") {
+		// Each line looks like "name:   type"
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
 		}
+		info := RemoteInfo{Name: strings.TrimSuffix(fields[0], ":"), RequiresConfigPassword: encrypted}
+		if len(fields) > 1 {
+			// Some types contain spaces, e.g. "google cloud storage"
+			info.Type = strings.Join(fields[1:], " ")
+		}
+		remotes = append(remotes, info)
 	}
 	return remotes, nil
 }
 
-// ListFiles returns the files and directories at the given remote path
-func ListFiles(remote, path string) ([]FileItem, error) {
-	remotePath := remote + ":" + path
-	cmd := exec.Command("rclone", "lsjson", remotePath)
-	output, err := cmd.Output()
+// ListRemotesAll returns every remote in the rclone config, parsed from
+// rclone config show, including those marked disabled
+func ListRemotesAll() ([]RemoteInfo, error) {
+	output, err := configCommand(context.Background(), "config", "show").Output()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list files at %s: %w", remotePath, err)
+		return nil, fmt.Errorf("failed to read config: %w", err)
 	}
+	encrypted, _ := ConfigEncrypted()
 
-	var items []FileItem
-	if err := json.Unmarshal(output, &items); err != nil {
-		return nil, fmt.Errorf("failed to parse file list: %w", err)
+	// The output is INI: a "[name]" line starts each remote's "key = value" lines
+	var remotes []RemoteInfo
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if name, ok := strings.CutPrefix(line, "["); ok {
+			remotes = append(remotes, RemoteInfo{Name: strings.TrimSuffix(name, "]"), RequiresConfigPassword: encrypted})
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok || len(remotes) == 0 {
+			continue
+		}
+		r := &remotes[len(remotes)-1]
+		switch strings.TrimSpace(k) {
+		case "type":
+			r.Type = strings.TrimSpace(v)
+		case "disable", "disabled":
+			r.Disabled, _ = strconv.ParseBool(strings.TrimSpace(v))
+		}
 	}
+	return remotes, nil
+}
 
-	// Update paths to be full paths
-	for i := range items {
-		if path == "" {
-			items[i].Path = items[i].Name
-		} else {
-			items[i].Path = path + "/" + items[i].Name
+// EnableRemote clears the disable setting of a remote
+func EnableRemote(name string) error {
+	output, err := configCommand(context.Background(), "config", "update", name, "disable", "false", "--non-interactive").CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("failed to enable remote %s: %w: %s", name, err, msg)
 		}
+		return fmt.Errorf("failed to enable remote %s: %w", name, err)
 	}
-
-	return items, nil
+	return nil
 }
 
-// Regex to match "Transferred:" lines
-// Example: "Transferred:   1.234 GiB / 5.678 GiB, 22%, 10 MiB/s, ETA 1m30s"
-var statsRegex = regexp.MustCompile(`Transferred:\s+([0-9.]+)\s*([kKMGTP]i?[Bb]?)\s*/\s*([0-9.]+)\s*([kKMGTP]i?[Bb]?),\s*([0-9]+)%`)
-
-// parseSize converts size string to bytes (e.g., "1.234" with unit "GiB")
-func parseSize(value, unit string) int64 {
-	val, err := strconv.ParseFloat(value, 64)
+// ListRemotesWithConfig returns the remotes configured in the rclone config
+// file at configPath, so remotes from several configs can be browsed in one
+// session
+func ListRemotesWithConfig(configPath string) ([]string, error) {
+	cmd := configCommand(WithRcloneConfig(context.Background(), configPath), "listremotes")
+	output, err := cmd.Output()
 	if err != nil {
-		return 0
+		return nil, fmt.Errorf("failed to list remotes in %s: %w", configPath, err)
 	}
 
-	// Normalize unit - handle both "MiB" and "MB" formats
-	unit = strings.ToUpper(strings.TrimSpace(unit))
-	unit = strings.TrimSuffix(unit, "B")
-	unit = strings.TrimSuffix(unit, "I") // Handle MiB vs MB
+	var remotes []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			remotes = append(remotes, strings.TrimSuffix(line, ":"))
+		}
+	}
+	return remotes, nil
+}
 
-	multiplier := int64(1)
-	switch unit {
-	case "K":
-		multiplier = 1024
-	case "M":
-		multiplier = 1024 * 1024
-	case "G":
-		multiplier = 1024 * 1024 * 1024
-	case "T":
-		multiplier = 1024 * 1024 * 1024 * 1024
-	case "P":
-		multiplier = 1024 * 1024 * 1024 * 1024 * 1024
+// ListRemotesGrouped returns the configured remote names keyed by backend type
+func ListRemotesGrouped() (map[string][]string, error) {
+	remotes, err := ListRemotesWithType()
+	if err != nil {
+		return nil, err
 	}
 
-	return int64(val * float64(multiplier))
+	groups := make(map[string][]string)
+	for _, r := range remotes {
+		groups[r.Type] = append(groups[r.Type], r.Name)
+	}
+	return groups, nil
 }
 
-// CopyFile copies a file from remote to local directory with progress updates via TransferManager
-func CopyFile(ctx context.Context, manager *TransferManager, transferID, remote, remotePath, localDir string) error {
-	src := remote + ":" + remotePath
+// EncryptedRemoteNames returns the names of remotes that use the crypt backend
+func EncryptedRemoteNames() ([]string, error) {
+	remotes, err := ListRemotesWithType()
+	if err != nil {
+		return nil, err
+	}
 
-	// Use -v (verbose) flag - this outputs "Transferred:" lines to stderr
-	// Use --stats to control update frequency
-	cmd := exec.CommandContext(ctx, "rclone", "copy", "-v", "--stats", "500ms", src, localDir)
+	var names []string
+	for _, r := range remotes {
+		if r.Type == "crypt" {
+			names = append(names, r.Name)
+		}
+	}
+	return names, nil
+}
 
-	stderr, err := cmd.StderrPipe()
+// GetCryptRemoteUnderlying returns the remote path that a crypt remote wraps
+func GetCryptRemoteUnderlying(remote string) (string, error) {
+	cfg, err := remoteConfig(remote)
 	if err != nil {
-		return fmt.Errorf("failed to create stderr pipe: %w", err)
+		return "", err
+	}
+	if cfg["type"] != "crypt" {
+		return "", fmt.Errorf("remote %s is not a crypt remote", remote)
 	}
+	return cfg["remote"], nil
+}
 
-	manager.Start(transferID)
+// CryptRemoteFor returns the crypt remote that wraps remote:path, whose
+// configuration can decrypt the names listed there
+func CryptRemoteFor(remote, path string) (string, error) {
+	dump, err := RemoteConfigs()
+	if err != nil {
+		return "", err
+	}
 
-	if err := cmd.Start(); err != nil {
-		manager.Fail(transferID, err)
-		return fmt.Errorf("failed to start rclone: %w", err)
+	path = strings.Trim(path, "/")
+	for name, cfg := range dump {
+		if cfg["type"] != "crypt" {
+			continue
+		}
+		wrappedRemote, wrappedPath, ok := strings.Cut(cfg["remote"], ":")
+		if !ok || wrappedRemote != remote {
+			continue
+		}
+		wrappedPath = strings.Trim(wrappedPath, "/")
+		if wrappedPath == "" || path == wrappedPath || strings.HasPrefix(path, wrappedPath+"/") {
+			return name, nil
+		}
 	}
+	return "", fmt.Errorf("no crypt remote wraps %s:%s", remote, path)
+}
 
-	// Parse progress output in a goroutine
-	done := make(chan struct{})
-	go func() {
-		defer close(done)
-		parseRcloneOutput(bufio.NewReader(stderr), transferID, manager)
-	}()
+// DecryptNames decrypts file or directory names stored by cryptRemote using
+// "rclone cryptdecode". Names that do not decrypt are left out of the result.
+func DecryptNames(cryptRemote string, names []string) (map[string]string, error) {
+	decrypted := make(map[string]string)
 
-	// Wait for command to complete
-	err = cmd.Wait()
+	// Keep each command line well below the OS argument limit
+	const chunk = 500
+	for start := 0; start < len(names); start += chunk {
+		end := start + chunk
+		if end > len(names) {
+			end = len(names)
+		}
 
-	// Wait for parsing to finish
-	<-done
+		args := append([]string{"cryptdecode", cryptRemote + ":"}, names[start:end]...)
+		output, err := configCommand(context.Background(), args...).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt names with %s: %w", cryptRemote, err)
+		}
+
+		// Each line is "<encrypted> \t <decrypted>"
+		for _, line := range strings.Split(string(output), "\n") {
+			enc, dec, ok := strings.Cut(line, " \t ")
+			if !ok || dec == "Failed to decrypt" {
+				continue
+			}
+			decrypted[enc] = dec
+		}
+	}
+	return decrypted, nil
+}
 
+// RemoteConfigs returns the key/value configuration of every configured remote
+func RemoteConfigs() (map[string]map[string]string, error) {
+	cmd := configCommand(context.Background(), "config", "dump")
+	output, err := cmd.Output()
 	if err != nil {
-		manager.Fail(transferID, err)
-		return err
+		return nil, fmt.Errorf("failed to read config: %w", err)
 	}
 
-	manager.Complete(transferID)
-	return nil
+	var dump map[string]map[string]string
+	if err := json.Unmarshal(output, &dump); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return dump, nil
 }
 
-// parseRcloneOutput parses rclone stderr output to extract progress information
-func parseRcloneOutput(reader *bufio.Reader, transferID string, mgr *TransferManager) {
-	scanner := bufio.NewScanner(reader)
+// remoteConfig returns the key/value configuration of a single remote
+func remoteConfig(remote string) (map[string]string, error) {
+	dump, err := RemoteConfigs()
+	if err != nil {
+		return nil, err
+	}
 
-	// Increase buffer size for long lines
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
+	cfg, ok := dump[remote]
+	if !ok {
+		return nil, fmt.Errorf("remote %s not found", remote)
+	}
+	return cfg, nil
+}
 
-	// Custom split function to handle both \r and \n
-	// This is critical because rclone uses \r to update progress lines in place
+// remoteTypes caches GetRemoteType results by remote name
+var remoteTypes sync.Map
+
+// GetRemoteType returns the backend type of a remote, such as "s3" or "drive"
+func GetRemoteType(remote string) (string, error) {
+	if t, ok := remoteTypes.Load(remote); ok {
+		return t.(string), nil
+	}
+	cfg, err := remoteConfig(remote)
+	if err != nil {
+		return "", err
+	}
+	remoteTypes.Store(remote, cfg["type"])
+	return cfg["type"], nil
+}
+
+// useFastList reports whether a listing of remote should pass --fast-list,
+// which only saves API calls on S3-compatible backends
+func useFastList(remote string, opts ListOptions) bool {
+	if !opts.FastList || opts.RcloneConfig != "" {
+		// Remote types are only known for the default config
+		return false
+	}
+	t, err := GetRemoteType(remote)
+	return err == nil && t == "s3"
+}
+
+// isSFTP reports whether the remote of a "remote:path" argument is an sftp
+// remote; local paths and unknown remotes are not
+func isSFTP(remotePath string) bool {
+	remote, _, ok := strings.Cut(remotePath, ":")
+	if !ok || remote == "" {
+		return false
+	}
+	t, err := GetRemoteType(remote)
+	return err == nil && t == "sftp"
+}
+
+// CreateRemote adds a new remote of the given backend type to the rclone config
+func CreateRemote(name, backend string, options map[string]string) error {
+	return createRemote(name, backend, options)
+}
+
+// CloneRemote adds dstName to the rclone config with the same type and
+// settings as srcName
+func CloneRemote(srcName, dstName string) error {
+	output, err := configCommand(context.Background(), "config", "show", srcName).Output()
+	if err != nil {
+		return fmt.Errorf("failed to read config of %s: %w", srcName, err)
+	}
+
+	// The output is the remote's INI section: "[name]" then "key = value" lines
+	options := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if k, v, ok := strings.Cut(line, "="); ok {
+			options[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+
+	backend := options["type"]
+	if backend == "" {
+		return fmt.Errorf("remote %s has no type", srcName)
+	}
+	delete(options, "type")
+
+	// Passwords in the config are already obscured
+	return createRemote(dstName, backend, options, "--no-obscure")
+}
+
+// createRemote runs "rclone config create" with options as key=value pairs
+func createRemote(name, backend string, options map[string]string, extraArgs ...string) error {
+	args := []string{"config", "create", name, backend}
+	keys := make([]string, 0, len(options))
+	for k := range options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, k+"="+options[k])
+	}
+	args = append(args, "--non-interactive")
+	args = append(args, extraArgs...)
+
+	output, err := configCommand(context.Background(), args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("failed to create remote %s: %w: %s", name, err, msg)
+		}
+		return fmt.Errorf("failed to create remote %s: %w", name, err)
+	}
+	return nil
+}
+
+// ListFilesWithMinSize lists the directories at the given remote path and
+// only the files of at least minBytes. Entries that fail to decode are skipped.
+func ListFilesWithMinSize(remote, path string, minBytes int64) ([]FileItem, error) {
+	remotePath := remote + ":" + path
+	// A bare number is KiB to rclone; the B suffix makes it bytes
+	cmd := configCommand(context.Background(), "lsjson", "--min-size", strconv.FormatInt(minBytes, 10)+"B", remotePath)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list large files at %s: %w", remotePath, err)
+	}
+
+	items, _ := ParseLsjsonOutput(output)
+	setFullPaths(items, path)
+	return items, nil
+}
+
+// ListFilesModifiedSince lists the directories at the given remote path and
+// only the files modified after since. Entries that fail to decode are skipped.
+func ListFilesModifiedSince(remote, path string, since time.Time) ([]FileItem, error) {
+	remotePath := remote + ":" + path
+	age := time.Since(since).Round(time.Second)
+	cmd := configCommand(context.Background(), "lsjson", "--max-age", age.String(), remotePath)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recent files at %s: %w", remotePath, err)
+	}
+
+	items, _ := ParseLsjsonOutput(output)
+	setFullPaths(items, path)
+	return items, nil
+}
+
+// ListFilesChangedSince lists remote:path and compares it with snapshot, an
+// earlier listing of the same path. Files are matched by path and count as
+// modified when their size or modification time differs.
+func ListFilesChangedSince(ctx context.Context, remote, path string, snapshot []FileItem) (added, modified, deleted []FileItem, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+	current, _, err := ListFiles(remote, path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	before := make(map[string]FileItem, len(snapshot))
+	for _, f := range snapshot {
+		before[f.Path] = f
+	}
+	for _, f := range current {
+		old, ok := before[f.Path]
+		switch {
+		case !ok:
+			added = append(added, f)
+		case old.Size != f.Size || old.ModTime != f.ModTime:
+			modified = append(modified, f)
+		}
+		delete(before, f.Path)
+	}
+	for _, f := range snapshot {
+		if _, ok := before[f.Path]; ok {
+			deleted = append(deleted, f)
+		}
+	}
+	return added, modified, deleted, nil
+}
+
+// ListFilesBetween lists the directories at the given remote path and only
+// the files modified between from and to. A zero from or to leaves that end
+// of the range open. Entries that fail to decode are skipped.
+func ListFilesBetween(ctx context.Context, remote, path string, from, to time.Time) ([]FileItem, error) {
+	remotePath := remote + ":" + path
+	args := []string{"lsjson"}
+	// rclone's age filters are durations back from now
+	if !from.IsZero() {
+		args = append(args, "--max-age", time.Since(from).Round(time.Second).String())
+	}
+	if !to.IsZero() {
+		args = append(args, "--min-age", time.Since(to).Round(time.Second).String())
+	}
+	args = append(args, remotePath)
+
+	output, err := configCommand(ctx, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files by date at %s: %w", remotePath, err)
+	}
+
+	items, _ := ParseLsjsonOutput(output)
+	setFullPaths(items, path)
+	return items, nil
+}
+
+// ListFilesFlat lists every file below the given remote path, leaving out
+// the directories. Each item's Name is its path relative to the listed
+// directory and its Path the full path. Entries that fail to decode are skipped.
+func ListFilesFlat(ctx context.Context, remote, path string) ([]FileItem, error) {
+	remotePath := remote + ":" + path
+	cmd := configCommand(ctx, "lsjson", "--recursive", remotePath)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files below %s: %w", remotePath, err)
+	}
+
+	items, _ := ParseLsjsonOutput(output)
+	files := items[:0]
+	for _, item := range items {
+		if item.IsDir {
+			continue
+		}
+		files = append(files, item)
+	}
+	setRelativeNames(files)
+	setFullPaths(files, path)
+	return files, nil
+}
+
+// ListDirTree lists the directories below remote:path, at most depth levels
+// deep, without listing any files. Names are relative to path.
+func ListDirTree(ctx context.Context, remote, path string, depth int) ([]FileItem, error) {
+	if depth < 1 {
+		return nil, fmt.Errorf("invalid tree depth %d", depth)
+	}
+	remotePath := remote + ":" + path
+	cmd := configCommand(ctx, "lsjson", "--dirs-only", "--recursive", "--max-depth", strconv.Itoa(depth), remotePath)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list directories below %s: %w", remotePath, err)
+	}
+
+	items, _ := ParseLsjsonOutput(output)
+	setRelativeNames(items)
+	setFullPaths(items, path)
+	return items, nil
+}
+
+// setRelativeNames names the items of a recursive listing by their path
+// relative to the listed directory, which is what lsjson puts in Path
+func setRelativeNames(items []FileItem) {
+	for i := range items {
+		items[i].Name = items[i].Path
+	}
+}
+
+// setFullPaths sets each item's Path to its full path below the listed directory
+func setFullPaths(items []FileItem, dir string) {
+	for i := range items {
+		if dir == "" {
+			items[i].Path = items[i].Name
+		} else {
+			items[i].Path = dir + "/" + items[i].Name
+		}
+	}
+}
+
+// ParseLsjsonOutput decodes the JSON array printed by rclone lsjson one element
+// at a time, so a malformed entry only loses that entry. It returns every item
+// that decoded and an error for each one that did not.
+func ParseLsjsonOutput(data []byte) ([]FileItem, []error) {
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	if tok, err := dec.Token(); err != nil {
+		return nil, []error{fmt.Errorf("failed to parse file list: %w", err)}
+	} else if tok != json.Delim('[') {
+		return nil, []error{fmt.Errorf("failed to parse file list: expected array, got %v", tok)}
+	}
+
+	var items []FileItem
+	var errs []error
+	for i := 0; dec.More(); i++ {
+		// Decoding into a raw message first keeps the decoder in sync when an
+		// element has the wrong shape
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			// Syntax errors leave the stream unreadable, so stop here
+			errs = append(errs, fmt.Errorf("failed to parse file list at item %d: %w", i, err))
+			break
+		}
+
+		var item FileItem
+		if err := json.Unmarshal(raw, &item); err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse file list item %d: %w", i, err))
+			continue
+		}
+		items = append(items, item)
+	}
+
+	return items, errs
+}
+
+// ListMode restricts a listing to files or directories
+type ListMode int
+
+const (
+	ListAll ListMode = iota
+	FilesOnly
+	DirsOnly
+)
+
+// String returns the short name of the mode shown in the browser
+func (m ListMode) String() string {
+	switch m {
+	case FilesOnly:
+		return "files"
+	case DirsOnly:
+		return "dirs"
+	default:
+		return "all"
+	}
+}
+
+// Next returns the mode after m, wrapping back to ListAll
+func (m ListMode) Next() ListMode {
+	return (m + 1) % 3
+}
+
+// ListOptions adjusts a directory listing
+type ListOptions struct {
+	// SortOrder sorts the listing once rclone has listed it: "name", "size",
+	// "modtime" or "mimetype", optionally followed by ",asc" or ",desc"
+	SortOrder string
+
+	// Mode lists only files or only directories
+	Mode ListMode
+
+	// FetchChecksums fills in FileItem.Hashes (--hash), which is slow on
+	// backends that have to read files to hash them
+	FetchChecksums bool
+
+	// MaxDepth lists this many levels below the path (--recursive
+	// --max-depth), naming entries by their path relative to it; zero and
+	// one list the path alone
+	MaxDepth int
+
+	// FastList lists S3-compatible remotes with --fast-list, which takes
+	// fewer API calls but more memory; other backends ignore it
+	FastList bool
+
+	// RcloneConfig lists with this rclone config file (--config) instead of
+	// the default one. Use WithRcloneConfig to copy or read the files listed.
+	RcloneConfig string
+}
+
+// ListFilesWithOptions is ListFiles with the listing adjusted by opts. lsjson
+// ignores --order-by, which only orders transfers, so sorting happens here.
+func ListFilesWithOptions(remote, path string, opts ListOptions) ([]FileItem, []error, error) {
+	items, errs, err := listFilesOpts(remote, path, opts)
+	if err != nil {
+		return nil, errs, err
+	}
+	if err := SortFiles(items, opts.SortOrder); err != nil {
+		return nil, nil, err
+	}
+	return items, errs, nil
+}
+
+// ListFilesWithMimeType is ListFiles with every entry's MimeType filled in,
+// guessed from the file extension where the backend gives none
+func ListFilesWithMimeType(remote, path string) ([]FileItem, []error, error) {
+	items, errs, err := listFilesOpts(remote, path, ListOptions{})
+	if err != nil {
+		return nil, errs, err
+	}
+	for i := range items {
+		items[i].MimeType = items[i].TypeOrGuess()
+	}
+	return items, errs, nil
+}
+
+// TypeOrGuess returns the file's MIME type, or one guessed from its extension
+// when the listing did not include it
+func (f FileItem) TypeOrGuess() string {
+	if f.MimeType != "" {
+		return f.MimeType
+	}
+	if f.IsDir {
+		return "inode/directory"
+	}
+	t := mime.TypeByExtension(path.Ext(f.Name))
+	if t == "" {
+		return "application/octet-stream"
+	}
+	// Drop parameters such as "; charset=utf-8"
+	t, _, _ = strings.Cut(t, ";")
+	return t
+}
+
+// ListFilesWithChecksums is ListFiles with every file's hashes filled in
+func ListFilesWithChecksums(remote, path string) ([]FileItem, []error, error) {
+	return ListFilesWithOptions(remote, path, ListOptions{FetchChecksums: true})
+}
+
+// SortFiles sorts items in place by a sort order such as "size,desc"; an empty
+// order leaves them as listed
+func SortFiles(items []FileItem, order string) error {
+	if order == "" {
+		return nil
+	}
+	less, err := SortLess(order)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(items, func(i, j int) bool { return less(items[i], items[j]) })
+	return nil
+}
+
+// SortLess returns the comparison for a sort order such as "size,desc"
+func SortLess(order string) (func(a, b FileItem) bool, error) {
+	key, dir, _ := strings.Cut(strings.ToLower(order), ",")
+
+	var less func(a, b FileItem) bool
+	switch key {
+	case "name":
+		less = func(a, b FileItem) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	case "size":
+		less = func(a, b FileItem) bool { return a.Size < b.Size }
+	case "modtime":
+		// RFC 3339 times in the same zone sort as strings
+		less = func(a, b FileItem) bool { return a.ModTime < b.ModTime }
+	case "mimetype":
+		// Not an rclone order; files of a type stay in name order
+		less = func(a, b FileItem) bool {
+			if ta, tb := a.TypeOrGuess(), b.TypeOrGuess(); ta != tb {
+				return ta < tb
+			}
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		}
+	default:
+		return nil, fmt.Errorf("unknown sort key %q: want name, size, modtime or mimetype", key)
+	}
+
+	switch dir {
+	case "", "asc", "ascending":
+		return less, nil
+	case "desc", "descending":
+		return func(a, b FileItem) bool { return less(b, a) }, nil
+	default:
+		return nil, fmt.Errorf("unknown sort direction %q: want asc or desc", dir)
+	}
+}
+
+// CopyFromStdin uploads everything read from data to the remote file at path
+// using "rclone rcat"
+func CopyFromStdin(ctx context.Context, data io.Reader, remote, path string) error {
+	remotePath := remote + ":" + path
+	cmd := configCommand(ctx, "rcat", remotePath)
+	cmd.Stdin = data
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("failed to write %s: %w: %s", remotePath, err, msg)
+		}
+		return fmt.Errorf("failed to write %s: %w", remotePath, err)
+	}
+	return nil
+}
+
+// Purge deletes remote:path and everything below it. Unlike delete it
+// ignores filters and removes the directory itself.
+func Purge(ctx context.Context, remote, path string) error {
+	remotePath := remote + ":" + path
+	cmd := configCommand(ctx, "purge", remotePath)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("failed to purge %s: %w: %s", remotePath, err, msg)
+		}
+		return fmt.Errorf("failed to purge %s: %w", remotePath, err)
+	}
+	return nil
+}
+
+// LsFast lists every file below the given remote path using "rclone ls", which
+// is much faster than lsjson but only reports sizes and names. Names are relative
+// to path; IsDir, ModTime and Path are left empty.
+func LsFast(remote, path string) ([]FileItem, error) {
+	remotePath := remote + ":" + path
+	cmd := configCommand(context.Background(), "ls", remotePath)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files at %s: %w", remotePath, err)
+	}
+
+	var items []FileItem
+	for _, line := range strings.Split(string(output), "\n") {
+		// Each line is the size right-aligned in a column, a space and the name
+		sizeStr, name, ok := strings.Cut(strings.TrimLeft(line, " "), " ")
+		if !ok || name == "" {
+			continue
+		}
+		size, err := strconv.ParseInt(sizeStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		items = append(items, FileItem{Name: name, Size: size})
+	}
+	return items, nil
+}
+
+// Stat returns the file or directory at the given remote path
+func Stat(ctx context.Context, remote, path string) (FileItem, error) {
+	remotePath := remote + ":" + path
+	cmd := configCommand(ctx, "lsjson", "--stat", remotePath)
+	output, err := cmd.Output()
+	if err != nil {
+		return FileItem{}, fmt.Errorf("failed to stat %s: %w", remotePath, err)
+	}
+
+	var item FileItem
+	if err := json.Unmarshal(output, &item); err != nil {
+		return FileItem{}, fmt.Errorf("failed to parse stat output: %w", err)
+	}
+	item.Path = path
+	return item, nil
+}
+
+// FileStat reports whether remote:path exists and its size, without listing
+// the directory around it. A path that is missing is not an error.
+func FileStat(ctx context.Context, remote, path string) (exists bool, size int64, err error) {
+	remotePath := remote + ":" + path
+	cmd := configCommand(ctx, "lsjson", "--stat", remotePath)
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && (exitErr.ExitCode() == 3 || exitErr.ExitCode() == 4) {
+			// rclone exits with 3 or 4 when the directory or file is not found
+			return false, 0, nil
+		}
+		return false, 0, fmt.Errorf("failed to stat %s: %w", remotePath, err)
+	}
+
+	var item FileItem
+	if err := json.Unmarshal(output, &item); err != nil {
+		return false, 0, fmt.Errorf("failed to parse stat output: %w", err)
+	}
+	return true, item.Size, nil
+}
+
+// PathExists reports whether a file or directory exists at the given remote path
+func PathExists(ctx context.Context, remote, path string) (bool, error) {
+	exists, _, err := FileStat(ctx, remote, path)
+	return exists, err
+}
+
+// Cat returns up to count bytes starting at offset from the file at the given
+// remote path. A count of zero or less reads to the end of the file.
+// Byte ranges require rclone 1.57 or newer.
+func Cat(ctx context.Context, remote, path string, offset, count int64) ([]byte, error) {
+	remotePath := remote + ":" + path
+	args := []string{"cat"}
+	if offset > 0 {
+		args = append(args, "--offset", strconv.FormatInt(offset, 10))
+	}
+	if count > 0 {
+		args = append(args, "--count", strconv.FormatInt(count, 10))
+	}
+	args = append(args, remotePath)
+
+	cmd := configCommand(ctx, args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", remotePath, err)
+	}
+	return output, nil
+}
+
+// ChecksumFile computes the hash of a single remote file using the given algorithm
+// (md5, sha1 or sha256)
+func ChecksumFile(ctx context.Context, remote, path, algo string) (string, error) {
+	switch algo {
+	case "md5", "sha1", "sha256":
+	default:
+		return "", fmt.Errorf("unsupported hash algorithm: %s", algo)
+	}
+
+	remotePath := remote + ":" + path
+	cmd := configCommand(ctx, "hashsum", algo, remotePath)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", remotePath, err)
+	}
+
+	// Output format is "hash  filename"
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("no %s hash returned for %s", algo, remotePath)
+	}
+	return fields[0], nil
+}
+
+// MD5Sum returns the MD5 hash of every file at remote:path keyed by file name
+// relative to path. A path naming a single file yields one entry.
+func MD5Sum(ctx context.Context, remote, path string) (map[string]string, error) {
+	return hashSums(ctx, "md5sum", remote, path)
+}
+
+// SHA1Sum returns the SHA-1 hash of every file at remote:path keyed by file name
+// relative to path. A path naming a single file yields one entry.
+func SHA1Sum(ctx context.Context, remote, path string) (map[string]string, error) {
+	return hashSums(ctx, "sha1sum", remote, path)
+}
+
+// hashSums runs an rclone hash command such as md5sum and parses its output
+func hashSums(ctx context.Context, command, remote, path string) (map[string]string, error) {
+	remotePath := remote + ":" + path
+	cmd := configCommand(ctx, command, remotePath)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s on %s: %w", command, remotePath, err)
+	}
+
+	// Each line is "hash  filename"; names may contain spaces
+	sums := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		hash, name, ok := strings.Cut(line, "  ")
+		if !ok || hash == "" {
+			continue
+		}
+		sums[name] = hash
+	}
+	return sums, nil
+}
+
+// Regex to match dry-run notices
+// Example: "NOTICE: dir/file.txt: Skipped copy as --dry-run is set (size 1.2Ki)"
+var dryRunRegex = regexp.MustCompile(`NOTICE:\s+(.+?):\s+(?:Not copying as --dry-run|Skipped copy as --dry-run)`)
+
+// DryRunCopy returns the files that a copy from remote to localDir would transfer
+func DryRunCopy(ctx context.Context, remote, remotePath, localDir string) ([]string, error) {
+	src := remote + ":" + remotePath
+	cmd := configCommand(ctx, "copy", "--dry-run", src, localDir)
+
+	// Notices are written to stderr
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("dry run of %s failed: %w", src, err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if matches := dryRunRegex.FindStringSubmatch(line); len(matches) >= 2 {
+			files = append(files, matches[1])
+		}
+	}
+	return files, nil
+}
+
+// Regex to match "Transferred:" lines
+// Example: "Transferred:   1.234 GiB / 5.678 GiB, 22%, 10 MiB/s, ETA 1m30s"
+var statsRegex = regexp.MustCompile(`Transferred:\s+([0-9.]+)\s*([kKMGTP]i?[Bb]?)\s*/\s*([0-9.]+)\s*([kKMGTP]i?[Bb]?),\s*([0-9]+)%`)
+
+// chunkRegex matches rclone's debug line for a finished multi-thread chunk
+var chunkRegex = regexp.MustCompile(`multi-thread copy: chunk ([0-9]+)/([0-9]+) .*finished`)
+
+// checkingRegex matches a file being compared, either as "Checking: name" or
+// as a " * name: checking" entry under the Checking: heading of the stats
+var checkingRegex = regexp.MustCompile(`^\s*Checking:\s+(\S.*)$|^\s*\*\s+(.+?):\s+checking$`)
+
+// terminalTitlePrefix starts the escape sequence rclone sets the terminal
+// title with, ESC ] 0 ; <title> BEL
+const terminalTitlePrefix = "\x1b]0;"
+
+// parseTitles records the terminal titles in rclone's stdout, which with
+// --progress-terminal-title gets one with the ETA every stats interval
+func parseTitles(reader *bufio.Reader, transferID string, mgr *TransferManager) {
+	for {
+		chunk, err := reader.ReadString('\a')
+		if i := strings.LastIndex(chunk, terminalTitlePrefix); i >= 0 && strings.HasSuffix(chunk, "\a") {
+			mgr.UpdateTitle(transferID, strings.TrimSuffix(chunk[i+len(terminalTitlePrefix):], "\a"))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// parseSize converts size string to bytes (e.g., "1.234" with unit "GiB")
+func parseSize(value, unit string) int64 {
+	val, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+
+	// Normalize unit - handle both "MiB" and "MB" formats
+	unit = strings.ToUpper(strings.TrimSpace(unit))
+	unit = strings.TrimSuffix(unit, "B")
+	unit = strings.TrimSuffix(unit, "I") // Handle MiB vs MB
+
+	multiplier := int64(1)
+	switch unit {
+	case "K":
+		multiplier = 1024
+	case "M":
+		multiplier = 1024 * 1024
+	case "G":
+		multiplier = 1024 * 1024 * 1024
+	case "T":
+		multiplier = 1024 * 1024 * 1024 * 1024
+	case "P":
+		multiplier = 1024 * 1024 * 1024 * 1024 * 1024
+	}
+
+	return int64(val * float64(multiplier))
+}
+
+// CopyOptions holds optional flags applied to copy operations
+type CopyOptions struct {
+	// CreateEmptyDirs recreates empty source subdirectories (--create-empty-src-dirs)
+	CreateEmptyDirs bool
+
+	// IgnoreExisting skips files that already exist at the destination (--ignore-existing)
+	IgnoreExisting bool
+
+	// PreserveMetadata copies file metadata such as timestamps (--metadata, rclone 1.59+)
+	PreserveMetadata bool
+
+	// SkipNewerAtDest skips files that are newer at the destination (--update)
+	SkipNewerAtDest bool
+
+	// Immutable fails rather than overwriting a different existing file (--immutable)
+	Immutable bool
+
+	// NoCheckDest copies without reading the destination back to compare
+	// it, for write-once destinations that cannot be read (--no-check-dest)
+	NoCheckDest bool
+
+	// DriveStopOnLimit makes Google Drive downloads stop at the daily
+	// download limit instead of retrying (--drive-stop-on-download-limit)
+	DriveStopOnLimit bool
+
+	// SFTPConcurrency is the number of requests rclone keeps in flight for
+	// each file on an SFTP remote (--sftp-concurrency); zero keeps rclone's default
+	SFTPConcurrency int
+
+	// LowConcurrency runs one transfer and one checker at a time, for remotes
+	// that throttle parallel requests (--transfers 1 --checkers 1)
+	LowConcurrency bool
+
+	// WriteChecksumFile writes an MD5 sum of each downloaded file to
+	// <filename>.md5 beside it, in md5sum format
+	WriteChecksumFile bool
+
+	// CutoffMode is how rclone stops once --max-transfer is reached: "hard",
+	// "soft" or "cautious" (--cutoff-mode); empty keeps rclone's default, hard
+	CutoffMode string
+
+	// RCAddr enables rclone's remote control API on this address when non-empty
+	RCAddr string
+
+	// Retry controls how transient failures are retried
+	Retry RetryConfig
+}
+
+// CutoffModes are the values rclone accepts for --cutoff-mode
+var CutoffModes = []string{"hard", "soft", "cautious"}
+
+// args returns the rclone command-line flags for the options
+func (o CopyOptions) args() []string {
+	var args []string
+	if o.CreateEmptyDirs {
+		args = append(args, "--create-empty-src-dirs")
+	}
+	if o.IgnoreExisting {
+		args = append(args, "--ignore-existing")
+	}
+	if o.PreserveMetadata {
+		args = append(args, "--metadata")
+	}
+	if o.SkipNewerAtDest {
+		args = append(args, "--update")
+	}
+	if o.Immutable {
+		args = append(args, "--immutable")
+	}
+	if o.NoCheckDest {
+		args = append(args, "--no-check-dest")
+	}
+	if o.DriveStopOnLimit {
+		args = append(args, "--drive-stop-on-download-limit")
+	}
+	if o.CutoffMode != "" {
+		args = append(args, "--cutoff-mode", o.CutoffMode)
+	}
+	if o.LowConcurrency {
+		args = append(args, lowConcurrencyArgs...)
+	}
+	if o.RCAddr != "" {
+		args = append(args, "--rc", "--rc-addr", o.RCAddr, "--rc-no-auth")
+	}
+	return args
+}
+
+// FreeSpace returns the bytes available to the current user on the
+// filesystem holding localDir
+func FreeSpace(localDir string) (int64, error) {
+	free, err := freeSpace(localDir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get free space of %s: %w", localDir, err)
+	}
+	return free, nil
+}
+
+// CopyFile copies a file from remote to local directory with progress updates via TransferManager
+func CopyFile(ctx context.Context, manager *TransferManager, transferID, remote, remotePath, localDir string) error {
+	return CopyFileWithOptions(ctx, manager, transferID, remote, remotePath, localDir, CopyOptions{})
+}
+
+// CopyFileWithRetryBackoff is CopyFile retrying transient failures with
+// exponential backoff and jitter as set by retry
+func CopyFileWithRetryBackoff(ctx context.Context, manager *TransferManager, transferID, remote, remotePath, localDir string, retry RetryConfig) error {
+	return CopyFileWithOptions(ctx, manager, transferID, remote, remotePath, localDir, CopyOptions{Retry: retry})
+}
+
+// CopyFileWithOptions is CopyFile with additional rclone flags
+func CopyFileWithOptions(ctx context.Context, manager *TransferManager, transferID, remote, remotePath, localDir string, opts CopyOptions) error {
+	src := remote + ":" + remotePath
+	if err := runTransfer(ctx, manager, transferID, "copy", src, localDir, opts); err != nil {
+		return err
+	}
+	return maybeWriteChecksumFile(ctx, manager, transferID, filepath.Join(localDir, path.Base(remotePath)), opts)
+}
+
+// maybeWriteChecksumFile writes the MD5 sum of a downloaded file to
+// <file>.md5 when opts ask for it. The transfer fails if the file cannot be
+// written, since whoever asked for it relies on it.
+func maybeWriteChecksumFile(ctx context.Context, manager *TransferManager, transferID, localFile string, opts CopyOptions) error {
+	if !opts.WriteChecksumFile {
+		return nil
+	}
+	sumFile := localFile + ".md5"
+	output, err := configCommand(ctx, "hashsum", "md5", localFile, "--output-file", sumFile).CombinedOutput()
+	if err != nil {
+		err = fmt.Errorf("failed to write checksum file %s: %w: %s", sumFile, err, strings.TrimSpace(string(output)))
+		manager.Fail(transferID, err)
+		return err
+	}
+	manager.SetChecksumFile(transferID, sumFile)
+	return nil
+}
+
+// CopyFileWithMetadata is CopyFileWithOptions with --metadata. Afterwards the local
+// copy's modification time is set to modTime, the remote's RFC 3339 timestamp,
+// for backends whose metadata does not carry it.
+func CopyFileWithMetadata(ctx context.Context, manager *TransferManager, transferID, remote, remotePath, localDir, modTime string, opts CopyOptions) error {
+	opts.PreserveMetadata = true
+	if err := CopyFileWithOptions(ctx, manager, transferID, remote, remotePath, localDir, opts); err != nil {
+		return err
+	}
+	if modTime == "" || opts.IgnoreExisting {
+		// A skipped existing file must keep its own timestamp
+		return nil
+	}
+	return ensureModTime(filepath.Join(localDir, path.Base(remotePath)), modTime)
+}
+
+// ensureModTime sets the modification time of localFile to modTime unless it already matches
+func ensureModTime(localFile, modTime string) error {
+	want, err := time.Parse(time.RFC3339Nano, modTime)
+	if err != nil {
+		return fmt.Errorf("failed to parse modification time %q: %w", modTime, err)
+	}
+	info, err := os.Stat(localFile)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", localFile, err)
+	}
+
+	// Allow for filesystems that store coarse timestamps
+	diff := info.ModTime().Sub(want)
+	if diff > -time.Second && diff < time.Second {
+		return nil
+	}
+	if err := os.Chtimes(localFile, want, want); err != nil {
+		return fmt.Errorf("failed to set modification time of %s: %w", localFile, err)
+	}
+	return nil
+}
+
+// ChunkedCutoff is the file size above which CopyChunked is worth using
+const ChunkedCutoff = 1 << 30
+
+// DefaultStreams returns the multi-thread stream count used when none is given
+func DefaultStreams() int {
+	return min(4, runtime.NumCPU())
+}
+
+// CopyChunked copies a large file using several parallel download streams
+func CopyChunked(ctx context.Context, manager *TransferManager, transferID, remote, remotePath, localDir string, streams int) error {
+	return CopyChunkedWithOptions(ctx, manager, transferID, remote, remotePath, localDir, streams, CopyOptions{})
+}
+
+// CopyChunkedWithOptions is CopyChunked with additional rclone flags. A stream
+// count of zero or less uses DefaultStreams. Debug logging (-vv) is enabled
+// because that is where rclone reports each finished chunk.
+func CopyChunkedWithOptions(ctx context.Context, manager *TransferManager, transferID, remote, remotePath, localDir string, streams int, opts CopyOptions) error {
+	if streams <= 0 {
+		streams = DefaultStreams()
+	}
+	src := remote + ":" + remotePath
+	err := runTransfer(ctx, manager, transferID, "copy", src, localDir, opts,
+		"-vv", "--multi-thread-streams", strconv.Itoa(streams), "--multi-thread-cutoff", "256M")
+	if err != nil {
+		return err
+	}
+	return maybeWriteChecksumFile(ctx, manager, transferID, filepath.Join(localDir, path.Base(remotePath)), opts)
+}
+
+// CopyFileRenameNew is CopyFileWithOptions, except that an existing local file
+// is kept and the download is saved under a new name such as "name (1).ext"
+func CopyFileRenameNew(ctx context.Context, manager *TransferManager, transferID, remote, remotePath, localDir string, opts CopyOptions) error {
+	dst := filepath.Join(localDir, path.Base(remotePath))
+	if _, err := os.Stat(dst); err != nil {
+		return CopyFileWithOptions(ctx, manager, transferID, remote, remotePath, localDir, opts)
+	}
+	src := remote + ":" + remotePath
+	return runTransfer(ctx, manager, transferID, "copyto", src, uniquePath(dst), opts)
+}
+
+// CopyDirRenameNew is CopyDir, except that an existing local directory is
+// kept and the download goes into a new one such as "name (1)"
+func CopyDirRenameNew(ctx context.Context, manager *TransferManager, transferID, remote, remotePath, localDir string, opts CopyOptions) error {
+	src := remote + ":" + remotePath
+	dst := uniquePath(filepath.Join(localDir, path.Base(remotePath)))
+	return runTransfer(ctx, manager, transferID, "copy", src, dst, opts)
+}
+
+// uniquePath returns p, or p with " (N)" added before the extension if p exists
+func uniquePath(p string) string {
+	if _, err := os.Stat(p); err != nil {
+		return p
+	}
+	ext := filepath.Ext(p)
+	base := strings.TrimSuffix(p, ext)
+	for n := 1; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, n, ext)
+		if _, err := os.Stat(candidate); err != nil {
+			return candidate
+		}
+	}
+}
+
+// CopyDir copies a remote directory into a directory of the same name inside localDir
+func CopyDir(ctx context.Context, manager *TransferManager, transferID, remote, remotePath, localDir string, opts CopyOptions) error {
+	src := remote + ":" + remotePath
+	dst := filepath.Join(localDir, path.Base(remotePath))
+	return runTransfer(ctx, manager, transferID, "copy", src, dst, opts)
+}
+
+// CopyMulti copies a list of files below remotePath to localDir in a single rclone
+// invocation, reporting combined progress for all files under transferID.
+// File names are relative to remotePath.
+func CopyMulti(ctx context.Context, manager *TransferManager, transferID, remote, remotePath, localDir string, files []string) error {
+	list, err := os.CreateTemp("", "rcloneb-files-*.txt")
+	if err != nil {
+		return fmt.Errorf("failed to create file list: %w", err)
+	}
+	defer os.Remove(list.Name())
+
+	for _, f := range files {
+		if _, err := fmt.Fprintln(list, f); err != nil {
+			list.Close()
+			return fmt.Errorf("failed to write file list: %w", err)
+		}
+	}
+	if err := list.Close(); err != nil {
+		return fmt.Errorf("failed to write file list: %w", err)
+	}
+
+	src := remote + ":" + remotePath
+	return runTransfer(ctx, manager, transferID, "copy", src, localDir, CopyOptions{}, "--files-from", list.Name())
+}
+
+// MirrorDir makes a local copy of a remote directory identical to the remote
+// using "rclone sync --delete-excluded". Unlike CopyDir it deletes local files
+// that no longer exist on the remote or match one of the exclude patterns.
+func MirrorDir(ctx context.Context, remote, remotePath, localDir string, excludes []string, manager *TransferManager, transferID string, opts CopyOptions) error {
+	src := remote + ":" + remotePath
+	dst := filepath.Join(localDir, path.Base(remotePath))
+
+	args := []string{"--delete-excluded"}
+	for _, e := range excludes {
+		args = append(args, "--exclude", e)
+	}
+	return runTransfer(ctx, manager, transferID, "sync", src, dst, opts, args...)
+}
+
+// runTransfer runs an rclone copy or sync from src to dst, feeding progress into
+// the manager. Transient failures are retried as set by opts.Retry.
+func runTransfer(ctx context.Context, manager *TransferManager, transferID, verb, src, dst string, opts CopyOptions, extraArgs ...string) error {
+	// Use -v (verbose) flag - this outputs "Transferred:" lines to stderr
+	// Use --stats to control update frequency
+	if manager.LowConcurrency() {
+		opts.LowConcurrency = true
+	}
+	// --progress-terminal-title writes the ETA to stdout with each stats line
+	args := []string{verb, "-v", "--stats", "500ms", "--progress-terminal-title"}
+	if opts.RCAddr != "" {
+		// Progress is polled from the rc API, so stderr is only read for errors
+		args = []string{verb}
+	} else if jsonLogSupported() {
+		// Structured stats replace matching the "Transferred:" text
+		args = append(args, "--use-json-log")
+	}
+	args = append(args, opts.args()...)
+	if opts.SFTPConcurrency > 0 && (isSFTP(src) || isSFTP(dst)) {
+		args = append(args, "--sftp-concurrency", strconv.Itoa(opts.SFTPConcurrency))
+	}
+	args = append(args, extraArgs...)
+	args = append(args, src, dst)
+
+	manager.Start(transferID)
+	estimateSize(ctx, manager, transferID, src)
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = runOnce(ctx, manager, transferID, args, opts.RCAddr)
+		if err == nil || attempt+1 >= opts.Retry.MaxAttempts || ctx.Err() != nil {
+			break
+		}
+
+		var delay time.Duration
+		if errors.Is(err, ErrRateLimited) {
+			// Backing off briefly only hits the limit again
+			delay = RateLimitPause
+		} else if isRetryable(err) {
+			delay = opts.Retry.Delay(attempt)
+		} else {
+			break
+		}
+		if !opts.LowConcurrency && manager.LowConcurrency() {
+			// The remote kept throttling this attempt, so go easier on it
+			opts.LowConcurrency = true
+			args = slices.Insert(args, len(args)-2, lowConcurrencyArgs...)
+		}
+		manager.Retry(transferID, attempt+1, delay, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+		manager.setStatus(transferID, StatusInProgress)
+	}
+
+	if err != nil {
+		manager.Fail(transferID, err)
+		return err
+	}
+
+	manager.Complete(transferID)
+	return nil
+}
+
+// runOnce runs a single rclone invocation and parses its progress output, or
+// polls its progress from the rc API on rcAddr when set
+func runOnce(ctx context.Context, manager *TransferManager, transferID string, args []string, rcAddr string) error {
+	cmd := configCommand(ctx, args...)
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start rclone: %w", err)
+	}
+
+	// Parse progress output in goroutines
+	titlesDone := make(chan struct{})
+	go func() {
+		defer close(titlesDone)
+		parseTitles(bufio.NewReader(stdout), transferID, manager)
+	}()
+	done := make(chan struct{})
+	var lastError string
+	go func() {
+		defer close(done)
+		if slices.Contains(args, "--use-json-log") {
+			lastError = parseJSONLog(bufio.NewReader(stderr), transferID, manager)
+		} else {
+			lastError = parseRcloneOutput(bufio.NewReader(stderr), transferID, manager)
+		}
+	}()
+
+	if rcAddr != "" {
+		pollCtx, stopPolling := context.WithCancel(ctx)
+		defer stopPolling()
+		go pollTransfers(pollCtx, rcAddr, 500*time.Millisecond, manager, transferID)
+	}
+
+	// Both pipes must be read to the end before Wait closes them
+	<-titlesDone
+	<-done
+	err = cmd.Wait()
+
+	if err != nil && strings.Contains(lastError, "immutable file modified") {
+		return fmt.Errorf("%w: %s", ErrImmutableConflict, lastError)
+	}
+	if err != nil && isRateLimit(lastError) {
+		return fmt.Errorf("%w: %s", ErrRateLimited, lastError)
+	}
+	if err != nil && lastError != "" {
+		return fmt.Errorf("%w: %s", err, lastError)
+	}
+	return err
+}
+
+// ErrImmutableConflict is returned when a copy with --immutable would have
+// overwritten an existing file that differs from the source
+var ErrImmutableConflict = errors.New("file already exists")
+
+// ErrRateLimited is returned when a remote refuses a transfer because a rate
+// or download limit was exceeded, such as Google Drive's
+var ErrRateLimited = errors.New("rate limited")
+
+// RateLimitPause is how long a rate limited transfer waits before retrying
+const RateLimitPause = 60 * time.Second
+
+// Substrings of rclone errors reporting a rate or download limit
+var rateLimitErrors = []string{"Error 429", "User rate limit exceeded", "downloadQuotaExceeded"}
+
+// isRateLimit reports whether an rclone log line reports a rate limit
+func isRateLimit(line string) bool {
+	for _, s := range rateLimitErrors {
+		if strings.Contains(line, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Substrings of rclone errors that are worth retrying
+var transientErrors = []string{"connection reset", "timeout", "timed out", "502", "503", "temporarily unavailable"}
+
+// Substrings of rclone errors that will never succeed on retry
+var permanentErrors = []string{"permission denied", "not found", "doesn't exist"}
+
+// isRetryable reports whether a copy error looks transient
+func isRetryable(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, s := range permanentErrors {
+		if strings.Contains(msg, s) {
+			return false
+		}
+	}
+	for _, s := range transientErrors {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRcloneOutput parses rclone stderr output to extract progress information.
+// It returns the last ERROR line seen so failures can be reported meaningfully.
+func parseRcloneOutput(reader *bufio.Reader, transferID string, mgr *TransferManager) string {
+	var lastError string
+
+	scanner := bufio.NewScanner(reader)
+
+	// Increase buffer size for long lines
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024)
+
+	// Custom split function to handle both \r and \n
+	// This is critical because rclone uses \r to update progress lines in place
 	scanner.Split(func(data []byte, atEOF bool) (advance int, token []byte, err error) {
 		if atEOF && len(data) == 0 {
 			return 0, nil, nil
@@ -333,12 +1880,27 @@ func parseRcloneOutput(reader *bufio.Reader, transferID string, mgr *TransferMan
 		return 0, nil, nil
 	})
 
+	chunksDone := 0
 	for scanner.Scan() {
 		line := scanner.Text()
 		if line == "" {
 			continue
 		}
 
+		// Chunks finish out of order, so count them rather than trusting N
+		if m := chunkRegex.FindStringSubmatch(line); m != nil {
+			if total, err := strconv.Atoi(m[2]); err == nil {
+				chunksDone++
+				mgr.UpdateChunks(transferID, chunksDone, total)
+			}
+			continue
+		}
+
+		if m := checkingRegex.FindStringSubmatch(line); m != nil {
+			mgr.SetChecking(transferID, strings.TrimSpace(m[1]+m[2]))
+			continue
+		}
+
 		// Try to match progress line
 		matches := statsRegex.FindStringSubmatch(line)
 		if len(matches) >= 6 {
@@ -351,7 +1913,16 @@ func parseRcloneOutput(reader *bufio.Reader, transferID string, mgr *TransferMan
 				mgr.UpdateProgress(transferID, percentage, copied, total, "")
 			}
 		}
+
+		if i := strings.Index(line, "ERROR :"); i >= 0 {
+			lastError = strings.TrimSpace(line[i+len("ERROR :"):])
+			mgr.noteError(lastError)
+			if isRateLimit(lastError) {
+				mgr.SetRateLimited(transferID)
+			}
+		}
 	}
+	return lastError
 }
 
 // FormatSize formats a file size in human-readable format
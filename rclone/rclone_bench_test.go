@@ -0,0 +1,219 @@
+package rclone
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// requireRclone skips tb when the rclone binary is not installed
+func requireRclone(tb testing.TB) {
+	tb.Helper()
+	if _, err := exec.LookPath("rclone"); err != nil {
+		tb.Skip("rclone not installed")
+	}
+}
+
+// writeBenchFiles creates n small files under a new temporary directory
+func writeBenchFiles(b *testing.B, n int) string {
+	b.Helper()
+	dir := b.TempDir()
+	for i := 0; i < n; i++ {
+		name := filepath.Join(dir, fmt.Sprintf("file%05d.txt", i))
+		if err := os.WriteFile(name, []byte(name), 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// BenchmarkListFiles lists a local directory through the ListFiles of this
+// build; run it with -tags librclone as well, after fetching the library as
+// list_librclone.go describes, to compare the two
+func BenchmarkListFiles(b *testing.B) {
+	requireListBackend(b)
+	for _, n := range []int{10, 1000} {
+		b.Run(fmt.Sprintf("%s/%d", listBackend, n), func(b *testing.B) {
+			dir := writeBenchFiles(b, n)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				items, _, err := ListFiles(":local", dir)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(items) != n {
+					b.Fatalf("listed %d files, want %d", len(items), n)
+				}
+			}
+		})
+	}
+}
+
+// writeBenchFile writes a file of size random bytes under a new temporary
+// directory and returns its path
+func writeBenchFile(b *testing.B, size int) string {
+	b.Helper()
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		b.Fatal(err)
+	}
+	name := filepath.Join(b.TempDir(), "bench.bin")
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		b.Fatal(err)
+	}
+	return name
+}
+
+// benchmarkCopy times b.N runs of copyFile, which copies src into dst,
+// reporting throughput; ErrSSHUnavailable skips the benchmark
+func benchmarkCopy(b *testing.B, src string, copyFile func(ctx context.Context, m *TransferManager, id, dst string) error) {
+	info, err := os.Stat(src)
+	if err != nil {
+		b.Fatal(err)
+	}
+	dst := b.TempDir()
+	b.SetBytes(info.Size())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m := NewTransferManager()
+		id := fmt.Sprintf("transfer_%d", i)
+		m.Add(id, src, dst, info.Size())
+		err := copyFile(context.Background(), m, id, dst)
+		if errors.Is(err, ErrSSHUnavailable) {
+			b.Skip("built without nativessh")
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.StopTimer()
+		if err := os.Remove(filepath.Join(dst, filepath.Base(src))); err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+	}
+}
+
+// BenchmarkCopySFTP copies an 8 MiB file from a local SFTP server through an
+// rclone subprocess and over native SSH; build with -tags nativessh for the
+// latter
+func BenchmarkCopySFTP(b *testing.B) {
+	addr, keyPath := startSFTPServer(b)
+	useSFTPRemote(b, "benchsftp", addr, keyPath)
+	src := writeBenchFile(b, 8<<20)
+
+	b.Run("rclone", func(b *testing.B) {
+		requireRclone(b)
+		benchmarkCopy(b, src, func(ctx context.Context, m *TransferManager, id, dst string) error {
+			return CopyFile(ctx, m, id, "benchsftp", src, dst)
+		})
+	})
+	b.Run("ssh", func(b *testing.B) {
+		benchmarkCopy(b, src, func(ctx context.Context, m *TransferManager, id, dst string) error {
+			return CopyFileSSH(ctx, m, id, addr, "bench", keyPath, src, dst)
+		})
+	})
+}
+
+// useS3Remote serves dir over S3 with rclone serve s3 until b finishes, and
+// points rclone at a config file holding an s3 remote named name for it
+func useS3Remote(b *testing.B, name, dir string) {
+	b.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		b.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	cmd := exec.Command("rclone", "serve", "s3", dir, "--addr", addr, "--auth-key", "bench,benchsecret")
+	if err := cmd.Start(); err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	for deadline := time.Now().Add(10 * time.Second); ; {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			b.Fatalf("rclone serve s3 did not start: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	conf := fmt.Sprintf("[%s]\ntype = s3\nprovider = Rclone\nendpoint = http://%s\naccess_key_id = bench\nsecret_access_key = benchsecret\n", name, addr)
+	path := filepath.Join(b.TempDir(), "rclone.conf")
+	if err := os.WriteFile(path, []byte(conf), 0o600); err != nil {
+		b.Fatal(err)
+	}
+	b.Setenv("RCLONE_CONFIG", path)
+	// The server is plain HTTP, and the S3 backend fails to start with a CA bundle set
+	b.Setenv("AWS_CA_BUNDLE", "")
+}
+
+// BenchmarkListFilesFastList lists a 10,000-file bucket, spread over 100
+// directories, with and without --fast-list
+func BenchmarkListFilesFastList(b *testing.B) {
+	requireRclone(b)
+	if listBackend != "exec" {
+		b.Skip("the bucket is served by the rclone binary")
+	}
+
+	root := b.TempDir()
+	for d := 0; d < 100; d++ {
+		dir := filepath.Join(root, "bucket", fmt.Sprintf("dir%03d", d))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			b.Fatal(err)
+		}
+		for f := 0; f < 100; f++ {
+			if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%03d.txt", f)), nil, 0o644); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	useS3Remote(b, "benchs3", root)
+
+	for _, fastList := range []bool{false, true} {
+		b.Run(fmt.Sprintf("fast-list=%t", fastList), func(b *testing.B) {
+			opts := ListOptions{Mode: FilesOnly, MaxDepth: 2, FastList: fastList}
+			for i := 0; i < b.N; i++ {
+				items, _, err := ListFilesWithOptions("benchs3", "bucket", opts)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(items) != 10000 {
+					b.Fatalf("listed %d files, want 10000", len(items))
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkSFTPConcurrency copies an 8 MiB file from a local SFTP server,
+// behind 20ms of latency each way, at several --sftp-concurrency settings
+func BenchmarkSFTPConcurrency(b *testing.B) {
+	requireRclone(b)
+	addr, keyPath := startSFTPServer(b)
+	useSFTPRemote(b, "benchsftplag", withLatency(b, addr, 20*time.Millisecond), keyPath)
+	src := writeBenchFile(b, 8<<20)
+
+	for _, n := range []int{8, 32, 64} {
+		b.Run(fmt.Sprintf("concurrency=%d", n), func(b *testing.B) {
+			benchmarkCopy(b, src, func(ctx context.Context, m *TransferManager, id, dst string) error {
+				return CopyFileWithOptions(ctx, m, id, "benchsftplag", src, dst, CopyOptions{SFTPConcurrency: n})
+			})
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package rclone
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryConfig controls how transfers are retried after transient failures
+type RetryConfig struct {
+	// MaxAttempts is the most times a transfer is run, including the first;
+	// values below 2 disable retries
+	MaxAttempts int
+
+	// BaseDelay is the wait before the first retry. Each later retry waits
+	// twice as long, plus up to BaseDelay of random jitter, capped at MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// DefaultRetryConfig returns the retry settings used when none are configured
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts: 4,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// Delay returns how long to wait before retry n, counting from zero:
+// BaseDelay * 2^n plus random jitter of up to BaseDelay, capped at MaxDelay
+func (c RetryConfig) Delay(n int) time.Duration {
+	if c.BaseDelay <= 0 {
+		return 0
+	}
+	// Stop doubling before the shift overflows
+	if n > 30 {
+		n = 30
+	}
+	d := c.BaseDelay<<n + time.Duration(rand.Int63n(int64(c.BaseDelay)))
+	if d < c.BaseDelay || (c.MaxDelay > 0 && d > c.MaxDelay) {
+		d = c.MaxDelay
+	}
+	return d
+}
+
+// LogBuffer keeps the most recent lines logged for a transfer
+type LogBuffer struct {
+	lines []string
+}
+
+// logBufferSize is how many lines a LogBuffer keeps
+const logBufferSize = 50
+
+// Append adds a timestamped line, dropping the oldest once the buffer is full
+func (b *LogBuffer) Append(line string) {
+	b.lines = append(b.lines, time.Now().Format("15:04:05")+" "+line)
+	if len(b.lines) > logBufferSize {
+		b.lines = b.lines[len(b.lines)-logBufferSize:]
+	}
+}
+
+// Lines returns a copy of the buffered lines, oldest first
+func (b *LogBuffer) Lines() []string {
+	return append([]string(nil), b.lines...)
+}
@@ -0,0 +1,74 @@
+package rclone
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CheckForUpdate asks "rclone selfupdate --check" whether a newer stable
+// rclone release than the installed one is available
+func CheckForUpdate() (available bool, latestVersion string, err error) {
+	output, err := configCommand(context.Background(), "selfupdate", "--check").Output()
+	if err != nil {
+		return false, "", fmt.Errorf("failed to check for rclone updates: %w", err)
+	}
+
+	// Lines look like "yours:  1.64.2" and "latest: 1.65.0  (released 2023-11-26)"
+	var yours, latest string
+	for _, line := range strings.Split(string(output), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "yours":
+			yours = fields[0]
+		case "latest":
+			latest = fields[0]
+		}
+	}
+	if yours == "" || latest == "" {
+		return false, "", fmt.Errorf("failed to parse rclone selfupdate output")
+	}
+	return versionLess(yours, latest), strings.TrimPrefix(latest, "v"), nil
+}
+
+// versionLess reports whether version a is older than b, comparing the
+// numeric parts of versions such as "v1.64.2" and ignoring suffixes like "-beta"
+func versionLess(a, b string) bool {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			return x < y
+		}
+	}
+	return false
+}
+
+// versionParts splits a version into its numeric parts
+func versionParts(v string) []int {
+	v = strings.TrimPrefix(v, "v")
+	v, _, _ = strings.Cut(v, "-")
+	var parts []int
+	for _, p := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
@@ -0,0 +1,122 @@
+package rclone
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// running holds the serve subprocesses started by this process, keyed by protocol
+var (
+	runningMu sync.Mutex
+	running   = make(map[string]*exec.Cmd)
+)
+
+// ServeWebDAV serves remote:path over WebDAV on addr until ctx is cancelled
+func ServeWebDAV(ctx context.Context, remote, path, addr string) error {
+	return serve(ctx, "webdav", remote, path, "--addr", addr)
+}
+
+// ServeS3 serves remote:path as an S3-compatible endpoint on addr until ctx is
+// cancelled. Clients authenticate with accessKey and secretKey.
+func ServeS3(ctx context.Context, remote, path, addr, accessKey, secretKey string) error {
+	return serve(ctx, "s3", remote, path, "--addr", addr, "--auth-key", accessKey+","+secretKey)
+}
+
+// ServeFTP serves remote:path over FTP on addr until ctx is cancelled.
+// Clients log in with user and pass.
+func ServeFTP(ctx context.Context, remote, path, addr, user, pass string) error {
+	return serve(ctx, "ftp", remote, path, "--addr", addr, "--user", user, "--pass", pass)
+}
+
+// ServeRestic serves remote:path as a restic REST server backend on addr
+// until ctx is cancelled. With appendOnly, clients cannot delete or modify
+// existing data.
+func ServeRestic(ctx context.Context, remote, path, addr string, appendOnly bool) error {
+	args := []string{"--addr", addr}
+	if appendOnly {
+		args = append(args, "--append-only")
+	}
+	return serve(ctx, "restic", remote, path, args...)
+}
+
+// ServerPIDs returns the process IDs of the running serve subprocesses keyed by protocol
+func ServerPIDs() map[string]int {
+	runningMu.Lock()
+	defer runningMu.Unlock()
+
+	pids := make(map[string]int, len(running))
+	for protocol, cmd := range running {
+		pids[protocol] = cmd.Process.Pid
+	}
+	return pids
+}
+
+// StopServers sends SIGTERM to every running serve subprocess and waits up to
+// timeout for them to exit, killing any that are left
+func StopServers(timeout time.Duration) {
+	runningMu.Lock()
+	for _, cmd := range running {
+		_ = cmd.Process.Signal(syscall.SIGTERM)
+	}
+	runningMu.Unlock()
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		runningMu.Lock()
+		n := len(running)
+		runningMu.Unlock()
+		if n == 0 {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	runningMu.Lock()
+	defer runningMu.Unlock()
+	for _, cmd := range running {
+		_ = cmd.Process.Kill()
+	}
+}
+
+// serve runs "rclone serve <protocol>" until it exits or ctx is cancelled.
+// Cancellation sends SIGTERM and waits for rclone to shut down cleanly.
+func serve(ctx context.Context, protocol, remote, path string, args ...string) error {
+	cmdArgs := append([]string{"serve", protocol, remote + ":" + path}, args...)
+	cmd := configCommand(ctx, cmdArgs...)
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	// Fall back to killing the process if it ignores SIGTERM
+	cmd.WaitDelay = 5 * time.Second
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start rclone serve %s: %w", protocol, err)
+	}
+	runningMu.Lock()
+	running[protocol] = cmd
+	runningMu.Unlock()
+
+	err := cmd.Wait()
+
+	runningMu.Lock()
+	delete(running, protocol)
+	runningMu.Unlock()
+
+	if ctx.Err() != nil {
+		// Stopped on request
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("rclone serve %s failed: %w: %s", protocol, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
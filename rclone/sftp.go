@@ -0,0 +1,100 @@
+package rclone
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// ErrSSHUnavailable is returned by CopyFileSSH when the file cannot be copied
+// over native SSH, because rcloneb was built without it or the remote's
+// credentials cannot be used directly; callers should fall back to CopyFile
+var ErrSSHUnavailable = errors.New("native SSH copy unavailable")
+
+// SFTPCredentials returns the address, user, private key file and
+// known_hosts file, if any, of an sftp remote. ok is false for other backends
+// and for remotes that log in some other way, such as with a password or an
+// encrypted key.
+func SFTPCredentials(remote string) (host, username, keyPath, knownHostsFile string, ok bool) {
+	cfg, err := remoteConfig(remote)
+	if err != nil || cfg["type"] != "sftp" || cfg["host"] == "" || cfg["key_file"] == "" || cfg["key_file_pass"] != "" {
+		return "", "", "", "", false
+	}
+
+	port := cfg["port"]
+	if port == "" {
+		port = "22"
+	}
+	username = cfg["user"]
+	if username == "" {
+		// rclone logs in as the current user by default
+		u, err := user.Current()
+		if err != nil {
+			return "", "", "", "", false
+		}
+		username = u.Username
+	}
+	if keyPath, err = expandHome(cfg["key_file"]); err != nil {
+		return "", "", "", "", false
+	}
+	if knownHostsFile, err = expandHome(cfg["known_hosts_file"]); err != nil {
+		return "", "", "", "", false
+	}
+	return cfg["host"] + ":" + port, username, keyPath, knownHostsFile, true
+}
+
+// expandHome expands a leading ~/ in p to the home directory, as rclone does
+// for the sftp backend's file settings
+func expandHome(p string) (string, error) {
+	rest, found := strings.CutPrefix(p, "~/")
+	if !found {
+		return p, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, rest), nil
+}
+
+// CopyFileSSH copies remotePath on host to localDir over SFTP, logging in as
+// username with the private key at keyPath, with progress updates via
+// TransferManager. Like rclone without known_hosts_file, any host key is
+// accepted. It returns ErrSSHUnavailable, before starting the transfer, if
+// rcloneb was built without the nativessh tag or the server cannot be
+// reached or logged in to with the key.
+func CopyFileSSH(ctx context.Context, manager *TransferManager, transferID, host, username, keyPath, remotePath, localDir string) error {
+	return copyFileSSH(ctx, manager, transferID, host, username, keyPath, "", remotePath, localDir, CopyOptions{})
+}
+
+// NativeSSHCompatible reports whether the native SSH copy can follow opts.
+// It always overwrites, so it cannot skip files or write checksum files, and
+// it has no bandwidth limit, which rclone takes from RCLONE_BWLIMIT or, with
+// remote control, from the transfer view. Retries and SFTP concurrency are
+// followed.
+func (o CopyOptions) NativeSSHCompatible() bool {
+	if _, ok := GetEnv("bwlimit"); ok || o.RCAddr != "" {
+		return false
+	}
+	return !o.IgnoreExisting && !o.SkipNewerAtDest && !o.Immutable && !o.WriteChecksumFile
+}
+
+// CopyFileSFTP copies a file from an sftp remote over native SSH when
+// possible, falling back to CopyFileWithOptions. The remote's
+// known_hosts_file, if set, is checked as rclone would.
+func CopyFileSFTP(ctx context.Context, manager *TransferManager, transferID, remote, remotePath, localDir string, opts CopyOptions) error {
+	if !nativeSSH || !opts.NativeSSHCompatible() {
+		// Skip reading the remote's config for a copy that cannot happen
+		return CopyFileWithOptions(ctx, manager, transferID, remote, remotePath, localDir, opts)
+	}
+	if host, username, keyPath, knownHostsFile, ok := SFTPCredentials(remote); ok {
+		err := copyFileSSH(ctx, manager, transferID, host, username, keyPath, knownHostsFile, remotePath, localDir, opts)
+		if !errors.Is(err, ErrSSHUnavailable) {
+			return err
+		}
+	}
+	return CopyFileWithOptions(ctx, manager, transferID, remote, remotePath, localDir, opts)
+}
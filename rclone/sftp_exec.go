@@ -0,0 +1,15 @@
+//go:build !nativessh
+
+package rclone
+
+import "context"
+
+// nativeSSH reports whether this build has an SSH client
+const nativeSSH = false
+
+// copyFileSSH is CopyFileSSH with a known_hosts file and copy options. Builds
+// without the nativessh tag have no SSH client, so it always returns
+// ErrSSHUnavailable.
+func copyFileSSH(ctx context.Context, manager *TransferManager, transferID, host, username, keyPath, knownHostsFile, remotePath, localDir string, opts CopyOptions) error {
+	return ErrSSHUnavailable
+}
@@ -0,0 +1,200 @@
+package rclone
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// startSFTPServer serves the local filesystem over SSH on a loopback port
+// until tb finishes, answering only sftp subsystem requests, like an
+// SFTP-only server. It returns the server's address and a private key file
+// that logs in to it as any user.
+func startSFTPServer(tb testing.TB) (addr, keyPath string) {
+	tb.Helper()
+
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	hostKey, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	clientPub, clientPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	authorized, err := ssh.NewPublicKey(clientPub)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(clientPriv, "")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	keyPath = filepath.Join(tb.TempDir(), "id_ed25519")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600); err != nil {
+		tb.Fatal(err)
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if !bytes.Equal(key.Marshal(), authorized.Marshal()) {
+				return nil, fmt.Errorf("unknown key for %s", conn.User())
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSSHConn(conn, config)
+		}
+	}()
+	return ln.Addr().String(), keyPath
+}
+
+// serveSSHConn serves the sessions of one SSH connection
+func serveSSHConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChan := range chans {
+		if newChan.ChannelType() != "session" {
+			newChan.Reject(ssh.UnknownChannelType, "only sessions are supported")
+			continue
+		}
+		ch, chReqs, err := newChan.Accept()
+		if err != nil {
+			continue
+		}
+		go serveSSHSession(ch, chReqs)
+	}
+}
+
+// serveSSHSession runs the sftp subsystem on a session
+func serveSSHSession(ch ssh.Channel, reqs <-chan *ssh.Request) {
+	for req := range reqs {
+		switch req.Type {
+		case "subsystem":
+			var payload struct{ Name string }
+			if ssh.Unmarshal(req.Payload, &payload) != nil || payload.Name != "sftp" {
+				req.Reply(false, nil)
+				continue
+			}
+			req.Reply(true, nil)
+			go func() {
+				defer ch.Close()
+				server, err := sftp.NewServer(ch)
+				if err != nil {
+					return
+				}
+				server.Serve()
+			}()
+		default:
+			req.Reply(false, nil)
+		}
+	}
+}
+
+// useSFTPRemote points rclone at a config file, for the rest of tb, holding
+// an sftp remote named name for the server at addr
+func useSFTPRemote(tb testing.TB, name, addr, keyPath string) {
+	tb.Helper()
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	// Hash and shell commands are turned off, since the server runs no commands
+	conf := fmt.Sprintf("[%s]\ntype = sftp\nhost = %s\nport = %s\nuser = bench\nkey_file = %s\nshell_type = none\nmd5sum_command = none\nsha1sum_command = none\n",
+		name, host, port, keyPath)
+	path := filepath.Join(tb.TempDir(), "rclone.conf")
+	if err := os.WriteFile(path, []byte(conf), 0o600); err != nil {
+		tb.Fatal(err)
+	}
+	tb.Setenv("RCLONE_CONFIG", path)
+	// Without a known_hosts file CopyFileSSH accepts the server's key, as rclone does
+	tb.Setenv("HOME", tb.TempDir())
+}
+
+// withLatency starts a proxy to addr, until tb finishes, that delays
+// everything sent through it by latency in each direction, and returns the
+// proxy's address. Data stays pipelined, as on a long link.
+func withLatency(tb testing.TB, addr string, latency time.Duration) string {
+	tb.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			client, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			server, err := net.Dial("tcp", addr)
+			if err != nil {
+				client.Close()
+				continue
+			}
+			go delayCopy(server, client, latency)
+			go delayCopy(client, server, latency)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// delayCopy copies src to dst, writing each chunk latency after it was read
+func delayCopy(dst, src net.Conn, latency time.Duration) {
+	type chunk struct {
+		due  time.Time
+		data []byte
+	}
+	chunks := make(chan chunk, 1024)
+	go func() {
+		defer close(chunks)
+		for {
+			buf := make([]byte, 32<<10)
+			n, err := src.Read(buf)
+			if n > 0 {
+				chunks <- chunk{time.Now().Add(latency), buf[:n]}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	for c := range chunks {
+		time.Sleep(time.Until(c.due))
+		if _, err := dst.Write(c.data); err != nil {
+			break
+		}
+	}
+	dst.Close()
+	src.Close()
+}
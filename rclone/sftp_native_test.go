@@ -0,0 +1,54 @@
+//go:build nativessh
+
+package rclone
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestCopyFileSSHKnownHosts(t *testing.T) {
+	addr, keyPath := startSFTPServer(t)
+	src := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(src, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A known_hosts file listing some other key for the server
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(addr)}, other) + "\n"
+	if err := os.WriteFile(knownHosts, []byte(line), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewTransferManager()
+	m.Add("t", src, t.TempDir(), 5)
+	err = copyFileSSH(context.Background(), m, "t", addr, "test", keyPath, knownHosts, src, t.TempDir(), CopyOptions{})
+	if !errors.Is(err, ErrSSHUnavailable) {
+		t.Errorf("copy with a mismatched host key = %v, want ErrSSHUnavailable", err)
+	}
+
+	dst := t.TempDir()
+	if err := copyFileSSH(context.Background(), m, "t", addr, "test", keyPath, "", src, dst, CopyOptions{}); err != nil {
+		t.Fatalf("copy without known_hosts_file: %v", err)
+	}
+	if got, err := os.ReadFile(filepath.Join(dst, "file.txt")); err != nil || string(got) != "hello" {
+		t.Errorf("copied file = %q, %v, want %q", got, err, "hello")
+	}
+}
@@ -0,0 +1,47 @@
+package rclone
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Size returns the number of files under remotePath ("remote:path") and
+// their total size from rclone size. Objects of unknown size, such as
+// Google Docs, are left out of the total.
+func Size(ctx context.Context, remotePath string) (count, bytes int64, err error) {
+	cmd := configCommand(ctx, "size", "--json", remotePath)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get size of %s: %w", remotePath, err)
+	}
+
+	var resp struct {
+		Count int64 `json:"count"`
+		Bytes int64 `json:"bytes"`
+	}
+	if err := json.Unmarshal(output, &resp); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse size of %s: %w", remotePath, err)
+	}
+	return resp.Count, resp.Bytes, nil
+}
+
+// estimateSize fills in the total of a transfer queued without one, so its
+// progress bar means something before rclone's first stats. A remote that
+// cannot report sizes leaves the total unknown.
+func estimateSize(ctx context.Context, manager *TransferManager, transferID, src string) {
+	t := manager.Get(transferID)
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	known := t.BytesTotal > 0
+	t.mu.Unlock()
+	if known {
+		return
+	}
+
+	if _, bytes, err := Size(ctx, src); err == nil && bytes > 0 {
+		manager.SetTotal(transferID, bytes)
+	}
+}
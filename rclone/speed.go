@@ -0,0 +1,53 @@
+package rclone
+
+import "time"
+
+// speedHistoryLen is how many one-second speed samples a transfer keeps
+const speedHistoryLen = 60
+
+// recordSpeed adds the average speed since the last sample to SpeedHistory,
+// once for every whole second that has passed, dropping the oldest samples
+// once the history is full; t.mu must be held
+func (t *Transfer) recordSpeed(now time.Time) {
+	if t.sampledAt.IsZero() {
+		t.sampledAt, t.sampledBytes = now, t.BytesCopied
+		return
+	}
+	elapsed := now.Sub(t.sampledAt)
+	seconds := int(elapsed / time.Second)
+	if seconds < 1 {
+		return
+	}
+
+	speed := float64(t.BytesCopied-t.sampledBytes) / elapsed.Seconds()
+	if speed < 0 {
+		// The byte count restarted with a retry
+		speed = 0
+	}
+	for i := 0; i < min(seconds, speedHistoryLen); i++ {
+		t.SpeedHistory = append(t.SpeedHistory, speed)
+	}
+	if len(t.SpeedHistory) > speedHistoryLen {
+		t.SpeedHistory = t.SpeedHistory[len(t.SpeedHistory)-speedHistoryLen:]
+	}
+	t.sampledAt, t.sampledBytes = now, t.BytesCopied
+}
+
+// GetSpeedGraph returns up to the last n per-second speed samples of a
+// transfer in bytes per second, oldest first
+func (m *TransferManager) GetSpeedGraph(id string, n int) []float64 {
+	m.mu.RLock()
+	t, exists := m.transfers[id]
+	m.mu.RUnlock()
+	if !exists {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	samples := t.SpeedHistory
+	if len(samples) > n {
+		samples = samples[len(samples)-n:]
+	}
+	return append([]float64(nil), samples...)
+}
@@ -0,0 +1,54 @@
+package rclone
+
+import (
+	"regexp"
+	"time"
+)
+
+// LowConcurrencyThreshold is how many throttling errors in a row switch a
+// TransferManager's later transfers to low-concurrency mode
+const LowConcurrencyThreshold = 3
+
+// LowConcurrencyPause is how long to wait between the files of a batch in
+// low-concurrency mode. Each file is copied by its own rclone process, so
+// --transfers 1 only slows directory copies; the pause spaces out the rest.
+const LowConcurrencyPause = 2 * time.Second
+
+// lowConcurrencyArgs limit rclone to one transfer and one checker at a time,
+// for remotes such as Mega and Box that throttle parallel requests
+var lowConcurrencyArgs = []string{"--transfers", "1", "--checkers", "1"}
+
+// throttleRegex matches rclone errors from a remote refusing requests as
+// too many (429) or being overloaded (503)
+var throttleRegex = regexp.MustCompile(`\b(429|503)\b|Too Many Requests|Service Unavailable`)
+
+// isThrottle reports whether an rclone error line says the remote is
+// throttling requests
+func isThrottle(line string) bool {
+	return isRateLimit(line) || throttleRegex.MatchString(line)
+}
+
+// noteError counts consecutive throttling errors from rclone's log, turning
+// on low-concurrency mode once there are LowConcurrencyThreshold of them.
+// Any other error breaks the run.
+func (m *TransferManager) noteError(line string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !isThrottle(line) {
+		m.throttled = 0
+		return
+	}
+	m.throttled++
+	if m.throttled >= LowConcurrencyThreshold {
+		m.lowConcurrency = true
+	}
+}
+
+// LowConcurrency reports whether repeated throttling has switched transfers
+// started from now on to one transfer and one checker at a time
+func (m *TransferManager) LowConcurrency() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lowConcurrency
+}
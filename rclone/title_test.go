@@ -0,0 +1,25 @@
+package rclone
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestParseTitles(t *testing.T) {
+	m := NewTransferManager()
+	m.Add("t", "remote:a", "/tmp", 0)
+
+	// rclone writes titles back to back, with no newlines
+	out := "\x1b]0;ETA: -\a\x1b]0;ETA: 1m30s\a\x1b]0;ETA: 12s\a"
+	parseTitles(bufio.NewReader(strings.NewReader(out)), "t", m)
+	if got := m.Get("t").ProgressTitle; got != "ETA: 12s" {
+		t.Errorf("ProgressTitle = %q, want %q", got, "ETA: 12s")
+	}
+
+	// A sequence cut off at EOF is ignored
+	parseTitles(bufio.NewReader(strings.NewReader("\x1b]0;ETA: 5")), "t", m)
+	if got := m.Get("t").ProgressTitle; got != "ETA: 12s" {
+		t.Errorf("ProgressTitle after a cut-off title = %q, want %q", got, "ETA: 12s")
+	}
+}
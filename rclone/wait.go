@@ -0,0 +1,50 @@
+package rclone
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Substrings of rclone errors from a remote that cannot be reached
+var connectionErrors = []string{
+	"connection refused", "connection reset", "no route to host", "network is unreachable",
+	"no such host", "i/o timeout", "timed out", "couldn't connect", "dial tcp",
+}
+
+// IsConnectionError reports whether a listing failed because the remote could
+// not be reached, judging by rclone's error output
+func IsConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		msg += " " + string(exitErr.Stderr)
+	}
+	msg = strings.ToLower(msg)
+	for _, s := range connectionErrors {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// WaitForRemote checks every interval whether remote can be reached, with a
+// listing that descends nowhere, until it can or ctx is done
+func WaitForRemote(ctx context.Context, remote string, interval time.Duration) error {
+	for {
+		if err := configCommand(ctx, "ls", remote+":", "--max-depth", "0").Run(); err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
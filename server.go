@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"rcloneb/rclone"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// activeServer tracks a running "rclone serve" subprocess
+type activeServer struct {
+	label  string // Human readable protocol name
+	url    string
+	detail string // Extra connection details such as credentials
+	cancel context.CancelFunc
+}
+
+// serverStoppedMsg is sent when a serve subprocess exits
+type serverStoppedMsg struct {
+	protocol string
+	err      error
+}
+
+// serverStartedMsg is sent shortly after a serve subprocess starts, once its PID is known
+type serverStartedMsg struct{}
+
+// orphanedServersMsg carries serve processes left running by a previous run
+type orphanedServersMsg struct {
+	servers []serverRecord
+}
+
+// serveFunc starts serving remote:path and blocks until ctx is cancelled
+type serveFunc func(ctx context.Context, remote, path string) error
+
+// toggleServer starts a server for the current remote path, or stops it if one is
+// already running for the protocol
+func (m *Model) toggleServer(protocol, label, url, detail string, fn serveFunc) tea.Cmd {
+	return m.toggleServerAt(protocol, label, url, detail, m.currentRemote, m.currentPath, fn)
+}
+
+// toggleServerAt is toggleServer for a given remote path
+func (m *Model) toggleServerAt(protocol, label, url, detail, remote, path string, fn serveFunc) tea.Cmd {
+	if srv, ok := m.servers[protocol]; ok {
+		// The stopped message removes the entry once rclone has exited
+		srv.cancel()
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.servers[protocol] = activeServer{label: label, url: url, detail: detail, cancel: cancel}
+
+	run := func() tea.Msg {
+		err := fn(ctx, remote, path)
+		return serverStoppedMsg{protocol: protocol, err: err}
+	}
+	started := tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return serverStartedMsg{}
+	})
+	return tea.Batch(run, started)
+}
+
+// stopServers stops every running server
+func (m *Model) stopServers() {
+	for _, srv := range m.servers {
+		srv.cancel()
+	}
+}
+
+// serverProtocols returns the running server protocols in a stable order
+func (m Model) serverProtocols() []string {
+	protocols := make([]string, 0, len(m.servers))
+	for p := range m.servers {
+		protocols = append(protocols, p)
+	}
+	sort.Strings(protocols)
+	return protocols
+}
+
+// toggleWebDAV starts or stops a WebDAV server for the current remote path
+func (m *Model) toggleWebDAV() tea.Cmd {
+	addr := m.config.ServeAddr
+	return m.toggleServer("webdav", "WebDAV", "http://"+addr+"/", "", func(ctx context.Context, remote, path string) error {
+		return rclone.ServeWebDAV(ctx, remote, path, addr)
+	})
+}
+
+// toggleS3 starts or stops an S3-compatible server for the current remote path.
+// Keys that are not configured are generated for each session.
+func (m *Model) toggleS3() tea.Cmd {
+	addr := m.config.S3Addr
+	accessKey := m.config.S3AccessKey
+	if accessKey == "" {
+		accessKey = randomKey(10)
+	}
+	secretKey := m.config.S3SecretKey
+	if secretKey == "" {
+		secretKey = randomKey(20)
+	}
+
+	detail := "access key " + accessKey + " • secret " + secretKey
+	return m.toggleServer("s3", "S3", "http://"+addr+"/", detail, func(ctx context.Context, remote, path string) error {
+		return rclone.ServeS3(ctx, remote, path, addr, accessKey, secretKey)
+	})
+}
+
+// toggleFTP starts or stops an FTP server for the current remote path. A
+// password that is not configured is generated for each session.
+func (m *Model) toggleFTP() tea.Cmd {
+	addr := m.config.FTPAddr
+	user := m.config.FTPUser
+	pass := m.config.FTPPass
+	if pass == "" {
+		pass = randomKey(8)
+	}
+
+	return m.toggleServer("ftp", "FTP", "ftp://"+user+"@"+addr+"/", "password "+pass, func(ctx context.Context, remote, path string) error {
+		return rclone.ServeFTP(ctx, remote, path, addr, user, pass)
+	})
+}
+
+// toggleRestic starts or stops a restic REST server for remote:path
+func (m *Model) toggleRestic(remote, path string) tea.Cmd {
+	addr := m.config.ResticAddr
+	appendOnly := m.config.ResticAppendOnly
+
+	detail := ""
+	if appendOnly {
+		detail = "append-only"
+	}
+	return m.toggleServerAt("restic", "Restic", "rest:http://"+addr+"/", detail, remote, path, func(ctx context.Context, remote, path string) error {
+		return rclone.ServeRestic(ctx, remote, path, addr, appendOnly)
+	})
+}
+
+// serversPath returns the location of servers.json
+func serversPath() string {
+	return filepath.Join(dataDir(), "servers.json")
+}
+
+// serverRecord identifies a serve process in servers.json
+type serverRecord struct {
+	Protocol string `json:"protocol"`
+	PID      int    `json:"pid"`
+
+	// Started is when the process started, which tells it apart from a later
+	// process given the same PID
+	Started string `json:"started"`
+}
+
+// running reports whether the recorded process is still running
+func (r serverRecord) running() bool {
+	return r.Started != "" && processStart(r.PID) == r.Started
+}
+
+// saveServers records the running serve subprocesses in servers.json, along
+// with those of previous runs that are still running, removing the file when
+// there are none
+func saveServers(pids map[string]int, orphans []serverRecord) error {
+	var records []serverRecord
+	for protocol, pid := range pids {
+		records = append(records, serverRecord{Protocol: protocol, PID: pid, Started: processStart(pid)})
+	}
+	for _, r := range orphans {
+		if r.running() {
+			records = append(records, r)
+		}
+	}
+
+	p := serversPath()
+	if len(records) == 0 {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove servers file: %w", err)
+		}
+		return nil
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].PID < records[j].PID })
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode servers: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	if err := os.WriteFile(p, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write servers file: %w", err)
+	}
+	return nil
+}
+
+// checkOrphanedServers returns a command that reports serve processes recorded
+// in servers.json by a previous run that are still running
+func checkOrphanedServers() tea.Cmd {
+	return func() tea.Msg {
+		data, err := os.ReadFile(serversPath())
+		if err != nil {
+			return nil
+		}
+
+		var records []serverRecord
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil
+		}
+
+		var alive []serverRecord
+		for _, r := range records {
+			if r.running() {
+				alive = append(alive, r)
+			}
+		}
+		if len(alive) == 0 {
+			_ = saveServers(nil, nil)
+			return nil
+		}
+		return orphanedServersMsg{servers: alive}
+	}
+}
+
+// processStart returns when the process with the given PID started, as ps
+// reports it, or "" if there is no such process or no ps, as on Windows
+func processStart(pid int) string {
+	output, err := exec.Command("ps", "-o", "lstart=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// randomKey returns a random hex string of n bytes
+func randomKey(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "rcloneb"
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"rcloneb/rclone"
+)
+
+// settingEntry describes one Config field in the settings view
+type settingEntry struct {
+	Name        string
+	Value       string
+	Description string
+	Toggle      func(*Config) // Nil for settings that can only be set by flag
+	Flag        string        // rclone flag, which can be overridden by its RCLONE_* env var
+	FlagSet     bool          // rcloneb passes Flag itself, so its env var has no effect
+	Warning     string        // Confirmed in a modal before the setting is turned on
+}
+
+// settingsEntries lists the settings shown in the settings view
+func settingsEntries(cfg Config) []settingEntry {
+	return []settingEntry{
+		{
+			Name:        "Create empty dirs",
+			Value:       onOff(cfg.CreateEmptyDirs),
+			Description: "Recreate empty source subdirectories when downloading a directory (--create-empty-src-dirs)",
+			Toggle:      func(c *Config) { c.CreateEmptyDirs = !c.CreateEmptyDirs },
+			Flag:        "create-empty-src-dirs",
+			FlagSet:     cfg.CreateEmptyDirs,
+		},
+		{
+			Name:        "Skip existing",
+			Value:       onOff(cfg.IgnoreExisting),
+			Description: "Skip files that already exist locally, regardless of size or modification time (--ignore-existing)",
+			Toggle:      func(c *Config) { c.IgnoreExisting = !c.IgnoreExisting },
+			Flag:        "ignore-existing",
+			FlagSet:     cfg.IgnoreExisting,
+		},
+		{
+			Name:        "Update mode",
+			Value:       onOff(cfg.SkipNewerAtDest),
+			Description: "Only download files that are newer on the remote than locally (--update)",
+			Toggle:      func(c *Config) { c.SkipNewerAtDest = !c.SkipNewerAtDest },
+			Flag:        "update",
+			FlagSet:     cfg.SkipNewerAtDest,
+		},
+		{
+			Name:        "Immutable",
+			Value:       onOff(cfg.Immutable),
+			Description: "Fail instead of overwriting local files that differ from the remote (--immutable)",
+			Toggle:      func(c *Config) { c.Immutable = !c.Immutable },
+			Flag:        "immutable",
+			FlagSet:     cfg.Immutable,
+		},
+		{
+			Name:        "No check dest",
+			Value:       onOff(cfg.NoCheckDest),
+			Description: "Copy without reading the destination back, for write-once storage such as tape or Glacier (--no-check-dest)",
+			Toggle:      func(c *Config) { c.NoCheckDest = !c.NoCheckDest },
+			Flag:        "no-check-dest",
+			FlagSet:     cfg.NoCheckDest,
+			Warning:     "This disables destination-side verification.",
+		},
+		{
+			Name:        "Drive limit stop",
+			Value:       onOff(cfg.DriveStopOnLimit),
+			Description: "Stop Google Drive downloads at the download limit, then retry after a minute (--drive-stop-on-download-limit)",
+			Toggle:      func(c *Config) { c.DriveStopOnLimit = !c.DriveStopOnLimit },
+			Flag:        "drive-stop-on-download-limit",
+			FlagSet:     cfg.DriveStopOnLimit,
+		},
+		{
+			Name:        "Low concurrency",
+			Value:       onOff(cfg.LowConcurrencyMode),
+			Description: "One transfer and one checker at a time, with a pause between files, for remotes such as Mega or Box that throttle requests; turned on by itself after 3 rate limit errors in a row (--low-concurrency)",
+			Toggle:      func(c *Config) { c.LowConcurrencyMode = !c.LowConcurrencyMode },
+		},
+		{
+			Name:        "Write .md5 files",
+			Value:       onOff(cfg.WriteChecksumFile),
+			Description: "Write the MD5 sum of every downloaded file to <filename>.md5 beside it (--write-md5)",
+			Toggle:      func(c *Config) { c.WriteChecksumFile = !c.WriteChecksumFile },
+		},
+		{
+			Name:        "Cutoff mode",
+			Value:       cutoffModeValue(cfg.CutoffMode),
+			Description: "How transfers stop at the --max-transfer limit (set with RCLONE_MAX_TRANSFER): hard stops at once, soft finishes transfers in flight, cautious starts none that could pass it (--cutoff-mode)",
+			Toggle:      func(c *Config) { c.CutoffMode = nextCutoffMode(c.CutoffMode) },
+			Flag:        "cutoff-mode",
+			FlagSet:     cfg.CutoffMode != "",
+		},
+		{
+			Name:        "Preserve metadata",
+			Value:       onOff(cfg.PreserveMetadata),
+			Description: "Copy file metadata such as modification times; needs rclone 1.59+ (--metadata)",
+			Toggle:      func(c *Config) { c.PreserveMetadata = !c.PreserveMetadata },
+			Flag:        "metadata",
+			FlagSet:     cfg.PreserveMetadata,
+		},
+		{
+			Name:        "Compress uploads",
+			Value:       onOff(cfg.CompressUploads),
+			Description: "Gzip files written to remotes, such as pasted clipboard text, adding .gz to their names (--compress-uploads)",
+			Toggle:      func(c *Config) { c.CompressUploads = !c.CompressUploads },
+		},
+		{
+			Name:        "Remote control",
+			Value:       onOff(cfg.UseRC) + " (" + cfg.RCAddr + ")",
+			Description: "Enable rclone's remote control API during transfers for live global stats (--rc, --rc-addr)",
+			Toggle:      func(c *Config) { c.UseRC = !c.UseRC },
+		},
+		{
+			Name:        "Thumbnails",
+			Value:       onOff(cfg.ShowThumbnails),
+			Description: "Preview images in the file browser on terminals with kitty graphics",
+			Toggle:      func(c *Config) { c.ShowThumbnails = !c.ShowThumbnails },
+		},
+		{
+			Name:        "Plain-text icons",
+			Value:       onOff(cfg.NoIcons),
+			Description: "Use text labels such as [ENC] instead of emoji",
+			Toggle:      func(c *Config) { c.NoIcons = !c.NoIcons },
+		},
+		{
+			Name:        "Fast listing",
+			Value:       onOff(cfg.FastListing),
+			Description: "Filter also matches files in subdirectories, found with the name-only rclone ls (--fast-listing)",
+			Toggle:      func(c *Config) { c.FastListing = !c.FastListing },
+		},
+		{
+			Name:        "S3 fast list",
+			Value:       onOff(cfg.FastList),
+			Description: "List S3 remotes with fewer API calls, using more memory on large buckets (--fast-list)",
+			Toggle:      func(c *Config) { c.FastList = !c.FastList },
+			Flag:        "fast-list",
+			FlagSet:     cfg.FastList,
+		},
+		{
+			Name:        "List checksums",
+			Value:       onOff(cfg.FetchChecksums),
+			Description: "List file hashes with each directory and cache them for verification; slow on some backends (--checksums)",
+			Toggle:      func(c *Config) { c.FetchChecksums = !c.FetchChecksums },
+		},
+		{
+			Name:        "Expand directories",
+			Value:       fmt.Sprintf("%s (%d levels)", onOff(cfg.ExpandDirs), cfg.MaxQueueDepth),
+			Description: "Queue the files inside a directory instead of the directory (--expand-dirs, --max-queue-depth)",
+			Toggle:      func(c *Config) { c.ExpandDirs = !c.ExpandDirs },
+		},
+		{
+			Name:        "Small file limit",
+			Value:       rclone.FormatSize(cfg.MinSizeFilter),
+			Description: "Files below this size are hidden while S hides small files (--min-size-filter)",
+		},
+		{
+			Name:        "Sort order",
+			Value:       sortOrderValue(cfg.SortOrder),
+			Description: "How directory listings are sorted; o in the browser cycles through orders (--sort)",
+		},
+		{
+			Name:        "Batch size",
+			Value:       batchSizeValue(cfg.BatchSize),
+			Description: "Start downloads automatically when the queue reaches this many items (--batch-size)",
+		},
+		{
+			Name:        "Retry attempts",
+			Value:       fmt.Sprintf("%d", cfg.Retry.MaxAttempts),
+			Description: "Times a transfer is run before transient errors such as timeouts or 502s fail it (--retry-attempts)",
+		},
+		{
+			Name:        "Retry base delay",
+			Value:       cfg.Retry.BaseDelay.String(),
+			Description: "Wait before the first retry, doubled with random jitter for each later one (--retry-base-delay)",
+		},
+		{
+			Name:        "Retry max delay",
+			Value:       cfg.Retry.MaxDelay.String(),
+			Description: "Longest wait between retries (--retry-max-delay)",
+		},
+		{
+			Name:        "SFTP concurrency",
+			Value:       sftpConcurrencyValue(cfg.SFTPConcurrency),
+			Description: "Requests kept in flight for each file on SFTP remotes; higher is faster on high-latency links (--sftp-concurrency)",
+			Flag:        "sftp-concurrency",
+			FlagSet:     cfg.SFTPConcurrency > 0,
+		},
+		{
+			Name:        "Queue save interval",
+			Value:       cfg.QueueSaveInterval.String(),
+			Description: "How long queue changes wait before the queue is saved to disk (--queue-save-interval)",
+		},
+		{
+			Name:        "Listing cache TTL",
+			Value:       cfg.CacheTTL.String(),
+			Description: "How long directory listings are reused (--cache-ttl)",
+		},
+		{
+			Name:        "Serve address",
+			Value:       cfg.ServeAddr,
+			Description: "Listen address used when serving a remote (--serve-addr)",
+		},
+		{
+			Name:        "S3 address",
+			Value:       cfg.S3Addr,
+			Description: "Listen address used when serving a remote over S3 (--s3-addr)",
+		},
+		{
+			Name:        "FTP address",
+			Value:       cfg.FTPAddr,
+			Description: "Listen address used when serving a remote over FTP (--ftp-addr)",
+		},
+		{
+			Name:        "Restic address",
+			Value:       cfg.ResticAddr,
+			Description: "Listen address used when serving a remote as a restic backend (--restic-addr)",
+		},
+		{
+			Name:        "Theme file",
+			Value:       cfg.ThemePath,
+			Description: "JSON color theme, reloaded on SIGHUP (--theme)",
+		},
+	}
+}
+
+// batchSizeValue formats the auto-start batch size
+func batchSizeValue(n int) string {
+	if n <= 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%d items", n)
+}
+
+// sftpConcurrencyValue formats the SFTP concurrency, where zero means rclone's default
+func sftpConcurrencyValue(n int) string {
+	if n <= 0 {
+		return "default"
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// cutoffModeValue describes the cutoff mode, where empty means rclone's default
+func cutoffModeValue(mode string) string {
+	if mode == "" {
+		return "hard (default)"
+	}
+	return mode
+}
+
+// nextCutoffMode returns the cutoff mode after mode, cycling back to rclone's default
+func nextCutoffMode(mode string) string {
+	i := slices.Index(rclone.CutoffModes, mode)
+	if i+1 >= len(rclone.CutoffModes) {
+		return ""
+	}
+	return rclone.CutoffModes[i+1]
+}
+
+// onOff formats a boolean setting
+func onOff(v bool) string {
+	if v {
+		return "on"
+	}
+	return "off"
+}
+
+// settingsView renders the settings view
+func (m Model) settingsView() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Settings"))
+	if m.configEncrypted {
+		b.WriteString(" " + cursorStyle.Render("[encrypted config]"))
+		if !rclone.ConfigPasswordSet() {
+			b.WriteString(" " + warningStyle.Render("set RCLONE_CONFIG_PASS to edit remotes"))
+		}
+	}
+	b.WriteString("\n\n")
+
+	entries := settingsEntries(m.config)
+	for i, e := range entries {
+		line := fmt.Sprintf(" %-20s %s", e.Name, e.Value)
+		if e.Flag != "" && !e.FlagSet {
+			if _, ok := rclone.GetEnv(e.Flag); ok {
+				line += " [env]"
+			}
+		}
+		if i == m.settingsIndex {
+			b.WriteString(selectedStyle.Render(line))
+		} else {
+			b.WriteString(normalStyle.Render(line))
+		}
+		b.WriteString("\n")
+	}
+
+	if m.settingsIndex >= 0 && m.settingsIndex < len(entries) {
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render(entries[m.settingsIndex].Description))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("j/k: navigate • space/enter: toggle • esc: back"))
+
+	if m.settingsWarning && m.settingsIndex >= 0 && m.settingsIndex < len(entries) {
+		e := entries[m.settingsIndex]
+		box := helpBoxStyle.Render(warningStyle.Render(e.Warning) + "\n\n" +
+			"Turn on " + e.Name + "?\n\n" + helpStyle.Render("y: turn on • any other key: cancel"))
+		return m.overlay(b.String(), box)
+	}
+	return b.String()
+}
+
+// sortOrderValue describes the listing sort order, where empty means rclone's default
+func sortOrderValue(order string) string {
+	if order == "" {
+		return "name,asc"
+	}
+	return order
+}
@@ -3,102 +3,158 @@ package main
 import "github.com/charmbracelet/lipgloss"
 
 var (
-	// Colors
-	primaryColor   = lipgloss.Color("62")  // Purple
-	secondaryColor = lipgloss.Color("241") // Gray
-	accentColor    = lipgloss.Color("86")  // Cyan
-	errorColor     = lipgloss.Color("196") // Red
-	successColor   = lipgloss.Color("82")  // Green
-	warningColor   = lipgloss.Color("214") // Orange
+	// Colors, set from the active Theme
+	primaryColor   lipgloss.Color
+	secondaryColor lipgloss.Color
+	accentColor    lipgloss.Color
+	errorColor     lipgloss.Color
+	successColor   lipgloss.Color
+	warningColor   lipgloss.Color
+	textColor      lipgloss.Color
+	highlightColor lipgloss.Color
+	statusBgColor  lipgloss.Color
+
+	// Styles, rebuilt by buildStyles whenever the theme changes
+	titleStyle            lipgloss.Style
+	statusBarStyle        lipgloss.Style
+	selectedStyle         lipgloss.Style
+	normalStyle           lipgloss.Style
+	dirStyle              lipgloss.Style
+	fileStyle             lipgloss.Style
+	cursorStyle           lipgloss.Style
+	checkedStyle          lipgloss.Style
+	successStyle          lipgloss.Style
+	bannerStyle           lipgloss.Style
+	helpStyle             lipgloss.Style
+	errorStyle            lipgloss.Style
+	warningStyle          lipgloss.Style
+	progressBarStyle      lipgloss.Style
+	progressCompleteStyle lipgloss.Style
+	queueItemStyle        lipgloss.Style
+	headerStyle           lipgloss.Style
+	filterPromptStyle     lipgloss.Style
+	filterTextStyle       lipgloss.Style
+	sizeStyle             lipgloss.Style
+	flashStyle            lipgloss.Style
+	paletteStyle          lipgloss.Style
+	spinnerStyle          lipgloss.Style
+)
+
+func init() {
+	applyTheme(DefaultTheme())
+}
 
+// buildStyles constructs every style from the current theme colors
+func buildStyles() {
 	// Title style
 	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(primaryColor).
-			MarginBottom(1)
+		Bold(true).
+		Foreground(primaryColor).
+		MarginBottom(1)
 
 	// Status bar style
 	statusBarStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			Background(lipgloss.Color("236")).
-			Padding(0, 1)
+		Foreground(secondaryColor).
+		Background(statusBgColor).
+		Padding(0, 1)
 
 	// Selected item style (highlighted bar)
 	selectedStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("255")).
-			Background(lipgloss.Color("62"))
+		Bold(true).
+		Foreground(highlightColor).
+		Background(primaryColor)
 
 	// Normal item style
 	normalStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("252"))
+		Foreground(textColor)
 
 	// Directory style
 	dirStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(primaryColor)
+		Bold(true).
+		Foreground(primaryColor)
 
 	// File style
 	fileStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("252"))
+		Foreground(textColor)
 
 	// Cursor style
 	cursorStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(accentColor)
+		Bold(true).
+		Foreground(accentColor)
 
 	// Checked item style (selected for queue)
 	checkedStyle = lipgloss.NewStyle().
-			Foreground(successColor)
+		Foreground(successColor)
 
 	// Success style
 	successStyle = lipgloss.NewStyle().
-			Foreground(successColor)
+		Foreground(successColor)
+
+	// Informational banner style
+	bannerStyle = lipgloss.NewStyle().
+		Foreground(warningColor).
+		Italic(true)
 
 	// Help style
 	helpStyle = lipgloss.NewStyle().
-			Foreground(secondaryColor).
-			MarginTop(1)
+		Foreground(secondaryColor).
+		MarginTop(1)
 
 	// Error style
 	errorStyle = lipgloss.NewStyle().
-			Foreground(errorColor).
-			Bold(true)
+		Foreground(errorColor).
+		Bold(true)
+
+	// Warning style for destructive operations
+	warningStyle = lipgloss.NewStyle().
+		Foreground(warningColor).
+		Bold(true)
 
 	// Progress bar styles
 	progressBarStyle = lipgloss.NewStyle().
-				Foreground(accentColor)
+		Foreground(accentColor)
 
 	progressCompleteStyle = lipgloss.NewStyle().
-				Foreground(successColor)
+		Foreground(successColor)
 
 	// Queue item style
 	queueItemStyle = lipgloss.NewStyle().
-			PaddingLeft(2)
+		PaddingLeft(2)
 
 	// Header style
 	headerStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(primaryColor).
-			BorderStyle(lipgloss.NormalBorder()).
-			BorderBottom(true).
-			BorderForeground(secondaryColor).
-			MarginBottom(1)
+		Bold(true).
+		Foreground(primaryColor).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderBottom(true).
+		BorderForeground(secondaryColor).
+		MarginBottom(1)
 
 	// Filter input style
 	filterPromptStyle = lipgloss.NewStyle().
-				Foreground(accentColor)
+		Foreground(accentColor)
 
 	filterTextStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("252"))
+		Foreground(textColor)
 
 	// Size style
 	sizeStyle = lipgloss.NewStyle().
-			Foreground(secondaryColor).
-			Width(10).
-			Align(lipgloss.Right)
+		Foreground(secondaryColor).
+		Width(10).
+		Align(lipgloss.Right)
+
+	// Flash message style
+	flashStyle = lipgloss.NewStyle().
+		Foreground(successColor).
+		Bold(true)
+
+	// Command palette overlay style
+	paletteStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primaryColor).
+		Padding(1, 2)
 
 	// Spinner style
 	spinnerStyle = lipgloss.NewStyle().
-			Foreground(accentColor)
-)
+		Foreground(accentColor)
+}
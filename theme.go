@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme holds the ANSI color values used to build every style
+type Theme struct {
+	Primary   string `json:"primary"`
+	Secondary string `json:"secondary"`
+	Accent    string `json:"accent"`
+	Error     string `json:"error"`
+	Success   string `json:"success"`
+	Warning   string `json:"warning"`
+	Text      string `json:"text"`
+	Highlight string `json:"highlight"`
+	StatusBg  string `json:"status_bg"`
+}
+
+// DefaultTheme returns the built-in color theme
+func DefaultTheme() Theme {
+	return Theme{
+		Primary:   "62",  // Purple
+		Secondary: "241", // Gray
+		Accent:    "86",  // Cyan
+		Error:     "196", // Red
+		Success:   "82",  // Green
+		Warning:   "214", // Orange
+		Text:      "252",
+		Highlight: "255",
+		StatusBg:  "236",
+	}
+}
+
+// defaultThemePath returns ~/.config/rcloneb/theme.json
+func defaultThemePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "rcloneb", "theme.json")
+}
+
+// LoadTheme reads a JSON theme file; colors missing from the file keep their defaults
+func LoadTheme(path string) (Theme, error) {
+	theme := DefaultTheme()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return theme, fmt.Errorf("failed to read theme: %w", err)
+	}
+	if err := json.Unmarshal(data, &theme); err != nil {
+		return theme, fmt.Errorf("failed to parse theme %s: %w", path, err)
+	}
+	return theme, nil
+}
+
+// applyTheme sets the color variables from the theme and rebuilds all styles
+func applyTheme(t Theme) {
+	primaryColor = lipgloss.Color(t.Primary)
+	secondaryColor = lipgloss.Color(t.Secondary)
+	accentColor = lipgloss.Color(t.Accent)
+	errorColor = lipgloss.Color(t.Error)
+	successColor = lipgloss.Color(t.Success)
+	warningColor = lipgloss.Color(t.Warning)
+	textColor = lipgloss.Color(t.Text)
+	highlightColor = lipgloss.Color(t.Highlight)
+	statusBgColor = lipgloss.Color(t.StatusBg)
+
+	buildStyles()
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // Register GIF decoder
+	_ "image/jpeg" // Register JPEG decoder
+	"image/png"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"rcloneb/rclone"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// graphicsProtocol identifies an inline image protocol supported by the terminal
+type graphicsProtocol int
+
+const (
+	graphicsNone graphicsProtocol = iota
+	graphicsKitty
+)
+
+const (
+	// Larger images are skipped rather than fetched for a preview
+	thumbnailFetchBytes = 8 << 20
+
+	// Size of the thumbnail area in terminal cells
+	thumbnailCols = 20
+	thumbnailRows = 10
+
+	// Screen row of the thumbnail's top edge
+	thumbnailRow = 4
+
+	// Maximum decoded thumbnail size in pixels
+	thumbnailMaxPixels = 160
+
+	// Terminal width required before thumbnails are shown
+	thumbnailMinWidth = 100
+
+	// Kitty image id of the thumbnail
+	thumbnailImageID = 1
+)
+
+// detectGraphicsProtocol guesses the terminal's image protocol from $TERM and
+// $TERM_PROGRAM. Sixel terminals are not detected: the renderer cuts every
+// view line to the window width, and sixel data cannot be made that short.
+func detectGraphicsProtocol() graphicsProtocol {
+	term := os.Getenv("TERM")
+	termProgram := os.Getenv("TERM_PROGRAM")
+
+	switch {
+	case term == "xterm-kitty", strings.Contains(term, "ghostty"),
+		termProgram == "WezTerm", termProgram == "ghostty":
+		return graphicsKitty
+	}
+	return graphicsNone
+}
+
+// isImageFile reports whether the file name has a supported image extension
+func isImageFile(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jpg", ".jpeg", ".png", ".gif":
+		return true
+	}
+	return false
+}
+
+// thumbnailLoadedMsg is sent when a thumbnail has been fetched and written
+// to a PNG file
+type thumbnailLoadedMsg struct {
+	path string
+	file string
+	err  error
+}
+
+// thumbnailsEnabled reports whether thumbnails can be shown right now
+func (m Model) thumbnailsEnabled() bool {
+	return m.config.ShowThumbnails && m.graphics != graphicsNone && m.width >= thumbnailMinWidth
+}
+
+// updateThumbnail requests a thumbnail for the image under the cursor and
+// drops the previous one when the cursor moves away
+func (m *Model) updateThumbnail() tea.Cmd {
+	var target string
+	if m.state == StateFileBrowser && !m.loading && m.thumbnailsEnabled() {
+		files := m.filteredFiles()
+		if m.fileIndex >= 0 && m.fileIndex < len(files) {
+			f := files[m.fileIndex]
+			if !f.IsDir && isImageFile(f.Name) && f.Size <= thumbnailFetchBytes {
+				target = f.Path
+			}
+		}
+	}
+
+	if target == m.thumbPath {
+		return nil
+	}
+
+	// The view deletes the image once m.thumbnail is empty
+	m.removeThumbnail()
+	m.thumbPath = target
+	if target == "" {
+		return nil
+	}
+	return loadThumbnail(m.currentRemote, target)
+}
+
+// removeThumbnail forgets the current thumbnail and deletes its file
+func (m *Model) removeThumbnail() {
+	if m.thumbnail != "" {
+		_ = os.Remove(m.thumbnail)
+		m.thumbnail = ""
+	}
+}
+
+// loadThumbnail returns a command that fetches an image, scales it down and
+// writes it to a temporary PNG file for the terminal to read
+func loadThumbnail(remote, filePath string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		// One byte more than the limit tells a cut-off image from a complete one
+		data, err := rclone.Cat(ctx, remote, filePath, 0, thumbnailFetchBytes+1)
+		if err != nil {
+			return thumbnailLoadedMsg{path: filePath, err: err}
+		}
+		if len(data) > thumbnailFetchBytes {
+			return thumbnailLoadedMsg{path: filePath, err: errThumbnailTooLarge}
+		}
+
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return thumbnailLoadedMsg{path: filePath, err: err}
+		}
+		img = scaleImage(img, thumbnailMaxPixels, thumbnailMaxPixels)
+
+		file, err := writeThumbnailFile(img)
+		return thumbnailLoadedMsg{path: filePath, file: file, err: err}
+	}
+}
+
+// errThumbnailTooLarge is returned for images bigger than thumbnailFetchBytes
+var errThumbnailTooLarge = errors.New("image too large for a thumbnail")
+
+// writeThumbnailFile encodes img as PNG into a new temporary file and
+// returns its path
+func writeThumbnailFile(img image.Image) (string, error) {
+	// A short name keeps the escape that names the file short
+	f, err := os.CreateTemp("", "rcb*.png")
+	if err != nil {
+		return "", fmt.Errorf("failed to create thumbnail file: %w", err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write thumbnail: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write thumbnail: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// withThumbnail adds the kitty escape that draws the current thumbnail, or
+// deletes it once there is none, to view. The image is sent as a file name
+// rather than inline data, because the renderer cuts every line to the
+// window width and counts the escape's payload as text. The escape moves the
+// cursor to the thumbnail area itself, so it goes on whichever line has room
+// for it. The renderer resends it whenever that line changes.
+func (m Model) withThumbnail(view string) string {
+	if m.graphics != graphicsKitty {
+		return view
+	}
+	seq := fmt.Sprintf("\x1b_Ga=d,d=I,i=%d,q=2\x1b\\", thumbnailImageID)
+	if m.thumbnail != "" && m.err == nil && m.state == StateFileBrowser && m.thumbnailsEnabled() {
+		name := base64.StdEncoding.EncodeToString([]byte(m.thumbnail))
+		// Save cursor, move, draw, restore so the renderer's cursor is untouched
+		seq = fmt.Sprintf("\x1b7\x1b[%d;%dH\x1b_Ga=T,f=100,t=f,i=%d,c=%d,r=%d,q=2;%s\x1b\\\x1b8",
+			thumbnailRow, m.width-thumbnailCols, thumbnailImageID, thumbnailCols, thumbnailRows, name)
+	}
+
+	// The renderer holds back an escape until a letter ends it, so finish
+	// with a style reset
+	seq += "\x1b[0m"
+
+	lines := strings.Split(view, "\n")
+	shortest := 0
+	for i, line := range lines {
+		if lipgloss.Width(line) < lipgloss.Width(lines[shortest]) {
+			shortest = i
+		}
+	}
+	if m.width > 0 && lipgloss.Width(lines[shortest])+lipgloss.Width(seq) > m.width {
+		return view
+	}
+	lines[shortest] += seq
+	return strings.Join(lines, "\n")
+}
+
+// scaleImage downsizes img with nearest-neighbour sampling to fit within maxW x maxH
+func scaleImage(img image.Image, maxW, maxH int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxW && h <= maxH {
+		return img
+	}
+
+	scale := float64(maxW) / float64(w)
+	if s := float64(maxH) / float64(h); s < scale {
+		scale = s
+	}
+	nw, nh := int(float64(w)*scale), int(float64(h)*scale)
+	if nw < 1 {
+		nw = 1
+	}
+	if nh < 1 {
+		nh = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, nw, nh))
+	for y := 0; y < nh; y++ {
+		for x := 0; x < nw; x++ {
+			dst.Set(x, y, img.At(b.Min.X+x*w/nw, b.Min.Y+y*h/nh))
+		}
+	}
+	return dst
+}
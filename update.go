@@ -3,13 +3,20 @@ package main
 import (
 	"context"
 	"fmt"
+	"log"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"rcloneb/queue"
 	"rcloneb/rclone"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -31,12 +38,44 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.transferCancel != nil {
 				m.transferCancel()
 			}
+			m.stopServers()
 			return m, tea.Quit
 		}
 
 		// Clear error on any key press
 		if m.err != nil {
 			m.err = nil
+			if m.waitOffered && msg.String() == "ctrl+r" {
+				m.waitOffered = false
+				ctx, cancel := context.WithCancel(context.Background())
+				m.waitCancel = cancel
+				m.loading = true
+				return m, tea.Batch(waitForRemote(ctx, m.currentRemote), m.spinner.Tick)
+			}
+			m.waitOffered = false
+			return m, nil
+		}
+
+		// Esc stops waiting for an unreachable remote
+		if m.waitCancel != nil && msg.String() == "esc" {
+			m.waitCancel()
+			return m, nil
+		}
+
+		// The help overlay takes every key until it is closed
+		if m.showHelp {
+			return m.updateHelp(msg)
+		}
+
+		// Open the command palette from any view except while typing into an input
+		if key.Matches(msg, m.keys.Palette) && m.state != StateCommandPalette && !m.typing() {
+			m.openPalette()
+			return m, textinput.Blink
+		}
+
+		// Help for the current view, unless "?" is being typed
+		if key.Matches(msg, m.keys.Help) && m.state != StateCommandPalette && !m.typing() {
+			m.showHelp = true
 			return m, nil
 		}
 
@@ -50,6 +89,38 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateQueueView(msg)
 		case StateTransferView:
 			return m.updateTransferView(msg)
+		case StateFileViewer:
+			return m.updateFileViewer(msg)
+		case StateQueuePreview:
+			return m.updateQueuePreview(msg)
+		case StateCommandPalette:
+			return m.updateCommandPalette(msg)
+		case StateSettings:
+			return m.updateSettings(msg)
+		case StateMirrorConfirm:
+			return m.updateMirrorConfirm(msg)
+		case StateNewRemote:
+			return m.updateNewRemote(msg)
+		case StateConfigWizard:
+			return m.updateConfigWizard(msg)
+		case StateDestinationInput:
+			return m.updateDestinationInput(msg)
+		case StateResumePrompt:
+			return m.updateResumePrompt(msg)
+		case StatePasteFile:
+			return m.updatePasteFile(msg)
+		case StatePurgeConfirm:
+			return m.updatePurgeConfirm(msg)
+		case StateImportFilelist:
+			return m.updateImportFilelist(msg)
+		case StateAuthorizing:
+			return m.updateAuthorizing(msg)
+		case StateCloneRemote:
+			return m.updateCloneRemote(msg)
+		case StateDiagnostics:
+			return m.updateDiagnostics(msg)
+		case StateQuotaSummary:
+			return m.updateQuotaSummary(msg)
 		}
 
 	case spinner.TickMsg:
@@ -63,24 +134,420 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 
 	case remotesLoadedMsg:
-		m.loading = false
+		// A remote opened from the command line may still be listing,
+		// possibly behind the resume prompt
+		if m.state == StateRemoteSelect || (m.state == StateResumePrompt && m.resumeReturn == StateRemoteSelect) {
+			m.loading = false
+		}
+		m.configEncrypted = msg.encrypted
 		if msg.err != nil {
 			m.err = msg.err
 			return m, nil
 		}
 		m.remotes = msg.remotes
+		m.remoteTypes = msg.types
+		m.cryptRemotes = make(map[string]bool)
+		for _, name := range msg.crypt {
+			m.cryptRemotes[name] = true
+		}
+		m.disabledRemotes = make(map[string]bool)
+		var enabled []string
+		for _, name := range msg.disabled {
+			m.disabledRemotes[name] = true
+		}
+		for _, name := range m.remotes {
+			if !m.disabledRemotes[name] {
+				enabled = append(enabled, name)
+			}
+		}
+		return m, loadQuotas(enabled)
+
+	case enabledMsg:
+		if msg.err != nil {
+			return m, m.showFlash(msg.err.Error(), 5*time.Second)
+		}
+		delete(m.disabledRemotes, msg.remote)
+		return m, tea.Batch(m.loadRemotes(), m.showFlash("Enabled "+msg.remote, 2*time.Second))
+
+	case diagnosticsMsg:
+		if m.state == StateDiagnostics && slices.Contains(m.diagRemotes, msg.remote) {
+			m.diagResults[msg.remote] = msg.results
+			m.loading = len(m.diagResults) < len(m.diagRemotes)
+		}
+		return m, nil
+
+	case quotaSummaryMsg:
+		if m.state == StateQuotaSummary && slices.Contains(m.summaryRemotes, msg.remote) {
+			if msg.err != nil {
+				m.summaryErrs[msg.remote] = msg.err
+			} else {
+				m.summaryQuotas[msg.remote] = msg.info
+				if msg.info.Total > 0 {
+					m.quotas[msg.remote] = msg.info
+				}
+			}
+			m.loading = len(m.summaryQuotas)+len(m.summaryErrs) < len(m.summaryRemotes)
+		}
+		return m, nil
+
+	case remoteReachableMsg:
+		m.waitCancel = nil
+		if msg.remote != m.currentRemote || m.state != StateFileBrowser {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.loading = false
+			return m, m.showFlash("Stopped waiting for "+msg.remote, 2*time.Second)
+		}
+		return m, tea.Batch(m.reloadFiles(), m.showFlash(msg.remote+" is reachable again", 2*time.Second))
+
+	case quotaLoadedMsg:
+		// Remotes without a quota simply show no bar
+		if msg.err == nil && msg.info.Total > 0 {
+			m.quotas[msg.info.Remote] = msg.info
+		}
+		return m, nil
+
+	case cryptInfoMsg:
+		if m.banner != "" && msg.remote == m.currentRemote {
+			m.banner = fmt.Sprintf("%s (wraps %s)", cryptBannerText, msg.underlying)
+		}
 		return m, nil
 
 	case filesLoadedMsg:
 		m.loading = false
 		if msg.err != nil {
 			m.err = msg.err
+			m.waitOffered = rclone.IsConnectionError(msg.err)
 			return m, nil
 		}
 		m.files = make([]BrowserItem, len(msg.files))
 		for i, f := range msg.files {
 			m.files[i] = BrowserItem{FileItem: f}
 		}
+		m.restoreCursor()
+		if m.fullListing() {
+			m.listings[m.listingKey()] = msg.files
+		}
+		if m.changesKey != m.listingKey() {
+			m.changes, m.changesKey = nil, ""
+		}
+		m.deepLoaded = false
+		m.decrypted = nil
+		m.cachedAt = msg.cachedAt
+		cmds := []tea.Cmd{m.updateThumbnail()}
+		if m.filterMode || m.filterText != "" {
+			cmds = append(cmds, m.loadDeepListing())
+		}
+		if m.config.DecryptNames {
+			cmds = append(cmds, m.decryptNames(true))
+		}
+		return m, tea.Batch(cmds...)
+
+	case namesDecryptedMsg:
+		// Ignore names for a directory we have since left
+		if msg.remote != m.currentRemote || msg.path != m.currentPath || !m.config.DecryptNames {
+			return m, nil
+		}
+		if msg.err != nil {
+			if msg.quiet {
+				return m, nil
+			}
+			return m, m.showFlash(msg.err.Error(), 3*time.Second)
+		}
+		m.decrypted = msg.names
+		return m, nil
+
+	case changesLoadedMsg:
+		return m.applyChanges(msg)
+
+	case deepListingMsg:
+		// Ignore listings for a directory we have since left
+		if msg.err != nil || m.deepLoaded || msg.remote != m.currentRemote || msg.path != m.currentPath || m.loading {
+			return m, nil
+		}
+		m.mergeDeepListing(msg.files)
+		if m.config.DecryptNames {
+			return m, m.decryptNames(true)
+		}
+		return m, nil
+
+	case themeReloadMsg:
+		theme, err := LoadTheme(m.config.ThemePath)
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		applyTheme(theme)
+		m.spinner.Style = spinnerStyle
+
+		// Re-send the window size to force a full re-render
+		size := tea.WindowSizeMsg{Width: m.width, Height: m.height}
+		return m, tea.Batch(
+			func() tea.Msg { return size },
+			m.showFlash("Theme reloaded", 1500*time.Millisecond),
+		)
+
+	case ncduExportedMsg:
+		m.flash = ""
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		return m, m.openDiskUsage(msg.file)
+
+	case ncduClosedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+		}
+		return m, nil
+
+	case extractMsg:
+		return m, m.handleExtract(msg)
+
+	case globalStatsMsg:
+		stats := msg.stats
+		m.globalStats = &stats
+		if stats.Speed > m.peakSpeed {
+			m.peakSpeed = stats.Speed
+		}
+		return m, waitForStats(msg.ch)
+
+	case serverStoppedMsg:
+		delete(m.servers, msg.protocol)
+		if msg.err != nil {
+			m.err = msg.err
+		}
+		if err := saveServers(rclone.ServerPIDs(), m.orphanServers); err != nil && m.config.Verbose {
+			log.Printf("saving server PIDs: %v", err)
+		}
+		return m, nil
+
+	case serverStartedMsg:
+		if err := saveServers(rclone.ServerPIDs(), m.orphanServers); err != nil && m.config.Verbose {
+			log.Printf("saving server PIDs: %v", err)
+		}
+		return m, nil
+
+	case orphanedServersMsg:
+		// Kept in servers.json until they exit, so later runs still report them
+		m.orphanServers = msg.servers
+		pids := make([]string, len(msg.servers))
+		for i, r := range msg.servers {
+			pids[i] = strconv.Itoa(r.PID)
+		}
+		return m, m.showFlash("rclone serve processes from a previous run may still be running (PID "+strings.Join(pids, ", ")+")", 5*time.Second)
+
+	case rcFlagSetMsg:
+		if msg.err != nil {
+			return m, m.showFlash("Failed to set "+msg.flag+": "+msg.err.Error(), 5*time.Second)
+		}
+		return m, m.showFlash(msg.flag+" set to "+msg.value, 3*time.Second)
+
+	case rcloneUpdateMsg:
+		m.rcloneUpdate = msg.version
+		return m, nil
+
+	case staleMountsMsg:
+		m.staleMounts = msg.mounts
+		return m, nil
+
+	case mountsUnmountedMsg:
+		m.staleMounts = msg.remaining
+		if msg.err != nil {
+			return m, m.showFlash(msg.err.Error(), 5*time.Second)
+		}
+		return m, m.showFlash("Unmounted stale mounts", 3*time.Second)
+
+	case interruptedMsg:
+		if len(msg.records) == 0 {
+			// Nothing left to resume, so drop any stale progress file
+			_ = saveProgress(nil)
+			return m, nil
+		}
+		m.interrupted = msg.records
+		m.resumeReturn = m.state
+		m.state = StateResumePrompt
+		return m, nil
+
+	case progressSaveMsg:
+		if m.transferMgr == nil {
+			// The finished batch was dismissed
+			_ = saveProgress(nil)
+			return m, nil
+		}
+		records := m.progressRecords()
+		if len(records) == 0 {
+			return m, saveProgressCmd(nil, m.config.Verbose)
+		}
+		return m, tea.Batch(saveProgressCmd(records, m.config.Verbose), saveProgressTick())
+
+	case fileWrittenMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.loading = true
+		flash := "Wrote " + msg.path
+		if msg.ratio > 0 {
+			flash += fmt.Sprintf(" [gzip, ~%.0f%% of original]", msg.ratio*100)
+		}
+		return m, tea.Batch(
+			m.reloadFiles(),
+			m.spinner.Tick,
+			m.showFlash(flash, 2*time.Second),
+		)
+
+	case purgedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.loading = true
+		return m, tea.Batch(
+			m.reloadFiles(),
+			m.spinner.Tick,
+			m.showFlash("Purged "+msg.remotePath, 2*time.Second),
+		)
+
+	case queueCheckedMsg:
+		if msg.err != nil {
+			return m, m.showFlash(msg.err.Error(), 3*time.Second)
+		}
+		if !msg.exists {
+			return m, m.showFlash(msg.file.Name+" no longer exists on "+msg.remote, 3*time.Second)
+		}
+		m.queue.Add(msg.remote, msg.file)
+		return m, m.maybeAutoStart()
+
+	case dirsExpandedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+		}
+		flash := m.showFlash(fmt.Sprintf("Queued %d files from the selected directories", msg.added), 2*time.Second)
+		return m, tea.Batch(flash, m.maybeAutoStart())
+
+	case filelistImportedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+		}
+		return m, m.showFlash(fmt.Sprintf("Queued %d files from the list", msg.added), 2*time.Second)
+
+	case backendsLoadedMsg:
+		// Without the list the type is simply typed by hand
+		if msg.err == nil {
+			m.backends = msg.backends
+		}
+		return m, nil
+
+	case remoteCreatedMsg:
+		m.creatingRemote = false
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		if msg.oauth {
+			return m, m.startOAuth(msg.name)
+		}
+		m.state = StateRemoteSelect
+		m.loading = true
+		return m, tea.Batch(
+			m.loadRemotes(),
+			m.spinner.Tick,
+			m.showFlash("Created remote "+msg.name, 2*time.Second),
+		)
+
+	case oauthStartedMsg:
+		if msg.err != nil {
+			// A nil cancel func means the user cancelled
+			if m.oauthCancel != nil {
+				m.oauthCancel = nil
+				m.err = msg.err
+			}
+			m.state = StateRemoteSelect
+			m.loading = true
+			return m, tea.Batch(m.loadRemotes(), m.spinner.Tick)
+		}
+		m.oauthURL = msg.url
+		// The URL stays on screen if no browser can be opened
+		_ = openURL(msg.url)
+		return m, waitOAuth(msg.remote, msg.done)
+
+	case oauthDoneMsg:
+		cancelled := m.oauthCancel == nil
+		if m.oauthCancel != nil {
+			m.oauthCancel()
+			m.oauthCancel = nil
+		}
+		m.state = StateRemoteSelect
+		m.loading = true
+		cmds := []tea.Cmd{m.loadRemotes(), m.spinner.Tick}
+		if msg.err != nil && !cancelled {
+			m.err = msg.err
+		} else if msg.err == nil {
+			cmds = append(cmds, m.showFlash("Authorized remote "+msg.remote, 2*time.Second))
+		}
+		return m, tea.Batch(cmds...)
+
+	case clearFlashMsg:
+		if msg.id == m.flashID {
+			m.flash = ""
+		}
+		return m, nil
+
+	case checksumMsg:
+		m.hashing = false
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.hashCache[msg.key] = msg.hash
+		return m, nil
+
+	case previewLoadedMsg:
+		if m.state != StateQueuePreview {
+			return m, nil
+		}
+		m.previewLoading = false
+		if msg.err != nil {
+			m.err = msg.err
+			m.state = StateQueueView
+			return m, nil
+		}
+		m.previewFiles = msg.files
+		for remotePath, size := range msg.sizes {
+			remote, path, _ := strings.Cut(remotePath, ":")
+			m.queue.SetSize(remote, path, size)
+			m.batch.SetSize(remote, path, size)
+		}
+		return m, nil
+
+	case viewerPageMsg:
+		// Drop pages for a file that is no longer being viewed
+		if msg.path != m.viewerPath || msg.offset != int64(len(m.viewerData)) {
+			return m, nil
+		}
+		m.viewerLoading = false
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.viewerData = append(m.viewerData, msg.data...)
+		if int64(len(msg.data)) < viewerPageSize {
+			m.viewerEOF = true
+		}
+		return m, nil
+
+	case thumbnailLoadedMsg:
+		// Ignore stale results if the cursor has already moved on
+		if msg.err != nil || msg.path != m.thumbPath || m.state != StateFileBrowser {
+			if msg.file != "" {
+				_ = os.Remove(msg.file)
+			}
+			return m, nil
+		}
+		m.removeThumbnail()
+		m.thumbnail = msg.file
 		return m, nil
 
 	case tickMsg:
@@ -89,6 +556,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		// Batch-triggered downloads return to the browser once finished,
+		// unless some failed; those stay on screen until enter is pressed
+		if m.autoStarted {
+			if pending, inProgress, _, failed := m.transferMgr.Stats(); pending == 0 && inProgress == 0 {
+				m.autoStarted = false
+				if failed > 0 {
+					return m, m.showFlash(fmt.Sprintf("%d downloads failed; they stay queued", failed), 5*time.Second)
+				}
+				m.finishBatch()
+				m.state = StateFileBrowser
+				return m, nil
+			}
+		}
+
 		// Always continue ticking while in transfer view
 		// This ensures the UI updates even during long transfers
 		return m, tickCmd()
@@ -100,6 +581,43 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // updateRemoteSelect handles input in remote selection view
 func (m Model) updateRemoteSelect(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.unmountConfirm {
+		m.unmountConfirm = false
+		if msg.String() == "y" {
+			return m, m.unmountStale()
+		}
+		return m, nil
+	}
+
+	if m.remoteMarking {
+		switch {
+		case key.Matches(msg, m.keys.Select):
+			if len(m.remotes) > 0 {
+				remote := m.remotes[m.selectedIndex]
+				if m.markedRemotes[remote] {
+					delete(m.markedRemotes, remote)
+				} else {
+					m.markedRemotes[remote] = true
+				}
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.DiagnoseMarked):
+			if marked := m.markedRemoteList(); len(marked) > 0 {
+				return m, m.openDiagnostics(marked...)
+			}
+			return m, m.showFlash("Mark remotes with space first", 2*time.Second)
+		case key.Matches(msg, m.keys.QuotaSummary):
+			if marked := m.markedRemoteList(); len(marked) > 0 {
+				return m, m.openQuotaSummary(marked)
+			}
+			return m, m.showFlash("Mark remotes with space first", 2*time.Second)
+		case key.Matches(msg, m.keys.MarkRemotes), key.Matches(msg, m.keys.Escape):
+			m.remoteMarking = false
+			clear(m.markedRemotes)
+			return m, nil
+		}
+	}
+
 	switch {
 	case key.Matches(msg, m.keys.Up):
 		if m.selectedIndex > 0 {
@@ -110,6 +628,9 @@ func (m Model) updateRemoteSelect(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.selectedIndex++
 		}
 	case key.Matches(msg, m.keys.Enter), key.Matches(msg, m.keys.Right):
+		if len(m.remotes) > 0 && m.disabledRemotes[m.remotes[m.selectedIndex]] {
+			return m, m.showFlash(m.remotes[m.selectedIndex]+" is disabled; press E to enable it", 3*time.Second)
+		}
 		if len(m.remotes) > 0 {
 			m.currentRemote = m.remotes[m.selectedIndex]
 			m.currentPath = ""
@@ -117,7 +638,45 @@ func (m Model) updateRemoteSelect(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.state = StateFileBrowser
 			m.loading = true
 			m.fileIndex = 0
-			return m, tea.Batch(m.loadFiles(), m.spinner.Tick)
+			cmds := []tea.Cmd{m.loadFiles(), m.spinner.Tick}
+			if m.cryptRemotes[m.currentRemote] && !m.bannerShown[m.currentRemote] {
+				m.bannerShown[m.currentRemote] = true
+				m.banner = cryptBannerText
+				cmds = append(cmds, loadCryptInfo(m.currentRemote))
+			}
+			return m, tea.Batch(cmds...)
+		}
+	case key.Matches(msg, m.keys.Settings):
+		m.openSettings()
+	case key.Matches(msg, m.keys.MarkRemotes):
+		if len(m.remotes) > 0 {
+			m.remoteMarking = true
+		}
+	case key.Matches(msg, m.keys.Diagnose):
+		if len(m.remotes) > 0 {
+			return m, m.openDiagnostics(m.remotes[m.selectedIndex])
+		}
+	case key.Matches(msg, m.keys.EnableRemote):
+		if len(m.remotes) > 0 && m.disabledRemotes[m.remotes[m.selectedIndex]] {
+			return m, enableRemote(m.remotes[m.selectedIndex])
+		}
+	case key.Matches(msg, m.keys.NewRemote):
+		return m, m.openNewRemote()
+	case key.Matches(msg, m.keys.DiskUsage):
+		if len(m.remotes) > 0 {
+			return m, m.exportDiskUsage(m.remotes[m.selectedIndex])
+		}
+	case key.Matches(msg, m.keys.UnmountStale):
+		if len(m.staleMounts) > 0 {
+			m.unmountConfirm = true
+		}
+	case key.Matches(msg, m.keys.Clone):
+		if len(m.remotes) > 0 {
+			m.state = StateCloneRemote
+			m.cloneInput.SetValue(m.remotes[m.selectedIndex] + "-copy")
+			m.cloneInput.CursorEnd()
+			m.cloneInput.Focus()
+			return m, textinput.Blink
 		}
 	case msg.String() == "q":
 		return m, tea.Quit
@@ -125,8 +684,132 @@ func (m Model) updateRemoteSelect(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// updateCloneRemote handles the name prompt for cloning the highlighted remote
+func (m Model) updateCloneRemote(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.cloneInput.Blur()
+		m.state = StateRemoteSelect
+		return m, nil
+	case "enter":
+		m.cloneInput.Blur()
+		m.state = StateRemoteSelect
+		return m, m.cloneRemote(m.remotes[m.selectedIndex])
+	}
+
+	var cmd tea.Cmd
+	m.cloneInput, cmd = m.cloneInput.Update(msg)
+	return m, cmd
+}
+
+// updateNewRemote handles input in the new remote form
+func (m Model) updateNewRemote(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.creatingRemote {
+		return m, nil
+	}
+
+	if key.Matches(msg, m.keys.Paste) {
+		if m.newRemoteFocus < len(m.newRemoteInputs) {
+			pasteInto(&m.newRemoteInputs[m.newRemoteFocus], m.clipboard)
+		}
+		return m, nil
+	}
+
+	// In the type field the arrows pick from the backend list instead
+	if m.newRemoteFocus == newRemoteType {
+		if matches := m.matchingBackends(); len(matches) > 0 {
+			switch msg.String() {
+			case "up":
+				if m.backendIndex > 0 {
+					m.backendIndex--
+				}
+				return m, nil
+			case "down":
+				if m.backendIndex < len(matches)-1 {
+					m.backendIndex++
+				}
+				return m, nil
+			case "enter":
+				if m.backendIndex < len(matches) {
+					m.chooseBackend(matches[m.backendIndex])
+				}
+				return m, m.focusNewRemote(m.newRemoteFocus + 1)
+			}
+		}
+	}
+
+	// Letters go to the inputs, so only arrows and tab move focus
+	switch msg.String() {
+	case "esc":
+		m.state = StateRemoteSelect
+		return m, nil
+	case "tab", "down":
+		return m, m.focusNewRemote(m.newRemoteFocus + 1)
+	case "shift+tab", "up":
+		return m, m.focusNewRemote(m.newRemoteFocus - 1)
+	case "enter":
+		switch m.newRemoteFocus {
+		case newRemoteDetect:
+			m.detected = detectCredentials()
+			if len(m.detected) == 0 {
+				return m, m.showFlash("No existing credentials found", 2*time.Second)
+			}
+			m.detectedIndex = 0
+			m.state = StateConfigWizard
+			return m, nil
+		case newRemoteCreate:
+			m.creatingRemote = true
+			return m, tea.Batch(m.createRemote(), m.spinner.Tick)
+		default:
+			return m, m.focusNewRemote(m.newRemoteFocus + 1)
+		}
+	}
+
+	if m.newRemoteFocus < len(m.newRemoteInputs) {
+		var cmd tea.Cmd
+		m.newRemoteInputs[m.newRemoteFocus], cmd = m.newRemoteInputs[m.newRemoteFocus].Update(msg)
+		if m.newRemoteFocus == newRemoteType {
+			m.backendIndex = 0
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+// updateConfigWizard handles input in the detected credentials list
+func (m Model) updateConfigWizard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.detectedIndex > 0 {
+			m.detectedIndex--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.detectedIndex < len(m.detected)-1 {
+			m.detectedIndex++
+		}
+	case key.Matches(msg, m.keys.Enter):
+		// The pre-filled values can still be edited before creating
+		m.applyCredential(m.detected[m.detectedIndex])
+		m.state = StateNewRemote
+		return m, m.focusNewRemote(newRemoteName)
+	case key.Matches(msg, m.keys.Escape):
+		m.state = StateNewRemote
+	}
+	return m, nil
+}
+
+// cryptBannerText is shown the first time a crypt remote is opened
+const cryptBannerText = "This remote encrypts filenames and content"
+
 // updateFileBrowser handles input in file browser view
 func (m Model) updateFileBrowser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// The informational banner is dismissed by any key
+	m.banner = ""
+
+	if m.dateMode {
+		return m.updateDateFilter(msg)
+	}
+
 	// Handle filter mode
 	if m.filterMode {
 		switch {
@@ -157,10 +840,32 @@ func (m Model) updateFileBrowser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.fileIndex > 0 {
 			m.fileIndex--
 		}
+		if m.rangeMode {
+			m.applyRange()
+		}
 	case key.Matches(msg, m.keys.Down):
 		if m.fileIndex < len(files)-1 {
 			m.fileIndex++
 		}
+		if m.rangeMode {
+			m.applyRange()
+		}
+	case key.Matches(msg, m.keys.Range):
+		if m.rangeMode {
+			m.rangeMode = false
+		} else {
+			m.startRange()
+		}
+	case key.Matches(msg, m.keys.RangeUp), key.Matches(msg, m.keys.RangeDown):
+		if !m.rangeMode {
+			m.startRange()
+		}
+		if key.Matches(msg, m.keys.RangeUp) && m.fileIndex > 0 {
+			m.fileIndex--
+		} else if key.Matches(msg, m.keys.RangeDown) && m.fileIndex < len(files)-1 {
+			m.fileIndex++
+		}
+		m.applyRange()
 	case key.Matches(msg, m.keys.Enter), key.Matches(msg, m.keys.Right):
 		if m.fileIndex >= 0 && m.fileIndex < len(files) {
 			f := files[m.fileIndex]
@@ -169,9 +874,8 @@ func (m Model) updateFileBrowser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.loading = true
 				return m, tea.Batch(m.loadFiles(), m.spinner.Tick)
 			} else {
-				// Add single file to queue
-				m.queue.Add(m.currentRemote, f.FileItem)
-				return m, nil
+				// Add single file to queue once it is known to still exist
+				return m, checkAndQueue(m.currentRemote, f.FileItem)
 			}
 		}
 	case key.Matches(msg, m.keys.Left), key.Matches(msg, m.keys.Back):
@@ -191,34 +895,289 @@ func (m Model) updateFileBrowser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keys.SelectAll):
 		m.selectAll()
 		return m, nil
+	case key.Matches(msg, m.keys.Mirror):
+		if m.fileIndex >= len(files) || !files[m.fileIndex].IsDir {
+			return m, m.showFlash("Only directories can be mirrored", 2*time.Second)
+		}
+		f := files[m.fileIndex]
+		m.queue.AddMirror(m.currentRemote, f.FileItem)
+		return m, m.showFlash("Queued mirror of "+f.Name+"/ (confirmed before starting)", 2*time.Second)
 	case key.Matches(msg, m.keys.Filter):
+		m.rangeMode = false
 		m.filterMode = true
 		m.filterInput.Focus()
-		return m, nil
+		return m, m.loadDeepListing()
 	case key.Matches(msg, m.keys.Escape):
+		m.rangeMode = false
 		if m.filterText != "" {
 			m.filterText = ""
 			m.filterInput.SetValue("")
 			m.fileIndex = 0
 		}
 	case key.Matches(msg, m.keys.Refresh):
+		m.loading = true
+		return m, tea.Batch(m.reloadFiles(), m.spinner.Tick)
+	case key.Matches(msg, m.keys.Recent):
+		m.showRecent = !m.showRecent
+		m.hideSmall = false
+		m.flatList = false
+		m.listMode = rclone.ListAll
+		m.dateFrom, m.dateTo = time.Time{}, time.Time{}
+		m.fileIndex = 0
+		m.loading = true
+		return m, tea.Batch(m.reloadFiles(), m.spinner.Tick)
+	case key.Matches(msg, m.keys.HideSmall):
+		// The listing takes one filter at a time, so this replaces recent mode
+		m.hideSmall = !m.hideSmall
+		m.showRecent = false
+		m.flatList = false
+		m.listMode = rclone.ListAll
+		m.dateFrom, m.dateTo = time.Time{}, time.Time{}
+		m.fileIndex = 0
+		m.loading = true
+		return m, tea.Batch(m.reloadFiles(), m.spinner.Tick)
+	case key.Matches(msg, m.keys.DateRange):
+		return m, m.openDateFilter()
+	case key.Matches(msg, m.keys.ShowChanges):
+		return m, m.showChanges()
+	case key.Matches(msg, m.keys.ListMode):
+		// The listing takes one filter at a time
+		m.listMode = m.listMode.Next()
+		m.showRecent = false
+		m.hideSmall = false
+		m.flatList = false
+		m.dateFrom, m.dateTo = time.Time{}, time.Time{}
+		m.fileIndex = 0
+		m.loading = true
+		return m, tea.Batch(m.reloadFiles(), m.spinner.Tick)
+	case key.Matches(msg, m.keys.FlatList):
+		m.flatList = !m.flatList
+		m.showRecent = false
+		m.hideSmall = false
+		m.listMode = rclone.ListAll
+		m.dateFrom, m.dateTo = time.Time{}, time.Time{}
+		m.fileIndex = 0
+		m.loading = true
+		return m, tea.Batch(m.reloadFiles(), m.spinner.Tick)
+	case key.Matches(msg, m.keys.Preview):
+		if m.fileIndex >= 0 && m.fileIndex < len(files) && !files[m.fileIndex].IsDir {
+			cmd := m.openViewer(files[m.fileIndex].Path)
+			return m, tea.Batch(cmd, m.updateThumbnail(), m.spinner.Tick)
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.Settings):
+		m.openSettings()
+		return m, m.updateThumbnail()
+	case key.Matches(msg, m.keys.WebDAV):
+		return m, m.toggleWebDAV()
+	case key.Matches(msg, m.keys.ServeS3):
+		return m, m.toggleS3()
+	case key.Matches(msg, m.keys.ServeFTP):
+		return m, m.toggleFTP()
+	case key.Matches(msg, m.keys.Paste):
+		m.state = StatePasteFile
+		m.pasteInput.SetValue("")
+		m.pasteInput.Focus()
+		return m, tea.Batch(textinput.Blink, m.updateThumbnail())
+	case key.Matches(msg, m.keys.Extract):
+		if m.fileIndex >= len(files) || files[m.fileIndex].IsDir || !isZipFile(files[m.fileIndex].Name) {
+			return m, m.showFlash("Only .zip files can be extracted", 2*time.Second)
+		}
+		if m.extract != nil {
+			return m, m.showFlash("Already extracting "+m.extract.name, 2*time.Second)
+		}
+		return m, m.startExtract(files[m.fileIndex])
+	case key.Matches(msg, m.keys.Sort):
+		// Cached listings are unsorted, so re-list to apply the new order
+		m.config.SortOrder = m.nextSortOrder()
 		m.loading = true
 		return m, tea.Batch(m.loadFiles(), m.spinner.Tick)
+	case key.Matches(msg, m.keys.DecryptNames):
+		m.config.DecryptNames = !m.config.DecryptNames
+		if !m.config.DecryptNames {
+			m.decrypted = nil
+			return m, nil
+		}
+		return m, m.decryptNames(false)
+	case key.Matches(msg, m.keys.Purge):
+		if m.fileIndex >= len(files) || !files[m.fileIndex].IsDir {
+			return m, m.showFlash("Only directories can be purged", 2*time.Second)
+		}
+		return m, tea.Batch(m.openPurgeConfirm(files[m.fileIndex].FileItem), m.updateThumbnail())
+	case key.Matches(msg, m.keys.Yank):
+		if m.fileIndex < len(files) {
+			m.clipboard = m.currentRemote + ":" + files[m.fileIndex].Path
+			return m, m.showFlash("Copied "+m.clipboard, 2*time.Second)
+		}
+	case key.Matches(msg, m.keys.Info):
+		m.showDetail = !m.showDetail
+		return m, nil
+	case key.Matches(msg, m.keys.Checksum):
+		if m.showDetail {
+			if cmd := m.computeChecksum(); cmd != nil {
+				return m, tea.Batch(cmd, m.spinner.Tick)
+			}
+		}
+		return m, nil
 	case msg.String() == "q":
 		// Add selected files to queue and go to queue view
-		m.addSelectedToQueue()
-		if m.queue.Len() > 0 {
+		expand := m.addSelectedToQueue()
+		if m.queue.Len() > 0 || expand != nil {
 			m.state = StateQueueView
 			m.selectedIndex = 0
 		}
+		return m, tea.Batch(expand, m.maybeAutoStart(), m.updateThumbnail())
+	}
+
+	return m, m.updateThumbnail()
+}
+
+// updateFileViewer handles input in the file viewer
+func (m Model) updateFileViewer(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	visibleLines := m.viewerVisibleLines()
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.viewerScroll > 0 {
+			m.viewerScroll--
+		}
+	case key.Matches(msg, m.keys.Down):
+		lines := m.viewerLines()
+		if m.viewerScroll+visibleLines < len(lines) {
+			m.viewerScroll++
+		}
+		// Fetch the next page once the end of the loaded content is visible
+		if m.viewerScroll+visibleLines >= len(lines) {
+			return m, m.loadViewerPage()
+		}
+	case key.Matches(msg, m.keys.Escape), key.Matches(msg, m.keys.Back), msg.String() == "q":
+		m.state = m.viewerReturn
+		m.viewerData = nil
+		m.viewerLoading = false
+		return m, m.updateThumbnail()
+	}
+
+	return m, nil
+}
+
+// openSettings switches to the settings view
+func (m *Model) openSettings() {
+	m.settingsReturn = m.state
+	m.settingsIndex = 0
+	m.state = StateSettings
+}
+
+// updateSettings handles input in the settings view
+func (m Model) updateSettings(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	entries := settingsEntries(m.config)
+
+	if m.settingsWarning {
+		m.settingsWarning = false
+		if msg.String() == "y" {
+			entries[m.settingsIndex].Toggle(&m.config)
+		}
 		return m, nil
 	}
 
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.settingsIndex > 0 {
+			m.settingsIndex--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.settingsIndex < len(entries)-1 {
+			m.settingsIndex++
+		}
+	case key.Matches(msg, m.keys.Select), key.Matches(msg, m.keys.Enter):
+		e := entries[m.settingsIndex]
+		if e.Toggle == nil {
+			break
+		}
+		if e.Warning != "" && e.Value == "off" {
+			m.settingsWarning = true
+			break
+		}
+		e.Toggle(&m.config)
+	case key.Matches(msg, m.keys.Escape), key.Matches(msg, m.keys.Settings):
+		m.state = m.settingsReturn
+		return m, m.updateThumbnail()
+	}
 	return m, nil
 }
 
+// updateCommandPalette handles input in the command palette
+func (m Model) updateCommandPalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	matches := m.paletteMatches()
+
+	switch msg.String() {
+	case "esc", "ctrl+p":
+		return m, m.closePalette()
+	case "up":
+		if m.paletteIndex > 0 {
+			m.paletteIndex--
+		}
+		return m, nil
+	case "down":
+		if m.paletteIndex < len(matches)-1 {
+			m.paletteIndex++
+		}
+		return m, nil
+	case "enter":
+		if m.paletteIndex >= 0 && m.paletteIndex < len(matches) {
+			cmd := m.runPaletteCommand(matches[m.paletteIndex])
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.paletteInput, cmd = m.paletteInput.Update(msg)
+	m.paletteIndex = 0
+	return m, cmd
+}
+
 // updateQueueView handles input in queue view
 func (m Model) updateQueueView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Handle search input
+	if m.queueSearchMode {
+		switch msg.String() {
+		case "esc":
+			m.queueSearchMode = false
+			m.queueSearchInput.Blur()
+			m.queueSearchInput.SetValue("")
+			return m, nil
+		case "enter":
+			m.queueSearchMode = false
+			m.queueSearchInput.Blur()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.queueSearchInput, cmd = m.queueSearchInput.Update(msg)
+		return m, cmd
+	}
+
+	// Handle tag editor input
+	if m.tagMode {
+		switch msg.String() {
+		case "esc":
+			m.tagMode = false
+			m.tagInput.Blur()
+			return m, nil
+		case "enter":
+			m.tagMode = false
+			m.tagInput.Blur()
+			m.queue.SetTags(m.selectedIndex, parseTags(m.tagInput.Value()))
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.tagInput, cmd = m.tagInput.Update(msg)
+		return m, cmd
+	}
+
+	if m.policyMenu {
+		return m.updateBulkPolicy(msg)
+	}
+
 	items := m.queue.Items()
 
 	switch {
@@ -231,40 +1190,290 @@ func (m Model) updateQueueView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.selectedIndex++
 		}
 	case key.Matches(msg, m.keys.Remove):
-		if len(items) > 0 {
+		if matches := m.queueMatchIndices(); len(matches) > 0 {
+			// Remove every search match, highest index first so indices stay valid
+			for i := len(matches) - 1; i >= 0; i-- {
+				m.queue.Remove(matches[i])
+			}
+			m.queueSearchInput.SetValue("")
+			m.selectedIndex = 0
+		} else if len(items) > 0 {
 			m.queue.Remove(m.selectedIndex)
 			if m.selectedIndex >= m.queue.Len() && m.selectedIndex > 0 {
 				m.selectedIndex--
 			}
 		}
+	case key.Matches(msg, m.keys.Find), key.Matches(msg, m.keys.Filter):
+		m.queueSearchMode = true
+		m.queueSearchInput.Focus()
+		return m, textinput.Blink
+	case key.Matches(msg, m.keys.Conflict):
+		if len(items) > 0 {
+			m.queue.CyclePolicy(m.selectedIndex)
+		}
+	case key.Matches(msg, m.keys.BulkPolicy):
+		if len(items) > 0 {
+			m.policyMenu = true
+			m.bulkPolicy = items[m.selectedIndex].Policy
+		}
+	case key.Matches(msg, m.keys.ClearDone):
+		before := len(items)
+		m.queue.Clear(queue.StatusCompleted)
+		if m.selectedIndex >= m.queue.Len() {
+			m.selectedIndex = max(m.queue.Len()-1, 0)
+		}
+		return m, m.showFlash(fmt.Sprintf("Cleared %d completed items", before-m.queue.Len()), 2*time.Second)
+	case key.Matches(msg, m.keys.Import):
+		if m.currentRemote == "" {
+			return m, m.showFlash("Open a remote first; listed paths are relative to its current directory", 2*time.Second)
+		}
+		m.state = StateImportFilelist
+		m.importInput.SetValue("")
+		m.importInput.Focus()
+		return m, textinput.Blink
+	case key.Matches(msg, m.keys.Tag):
+		if len(items) > 0 {
+			m.tagMode = true
+			m.tagInput.SetValue(strings.Join(items[m.selectedIndex].Tags, ", "))
+			m.tagInput.CursorEnd()
+			m.tagInput.Focus()
+			return m, textinput.Blink
+		}
+	case key.Matches(msg, m.keys.Pause):
+		if _, ok := m.queueSearchTag(); ok {
+			m.queue.ToggleHeld(m.queueMatchIndices())
+		} else if len(items) > 0 {
+			m.queue.ToggleHeld([]int{m.selectedIndex})
+		}
+	case key.Matches(msg, m.keys.ChangeDest):
+		m.state = StateDestinationInput
+		m.destInput.SetValue(downloadDir())
+		for _, item := range items {
+			if item.Status == queue.StatusPending && item.LocalPath != "" {
+				m.destInput.SetValue(item.LocalPath)
+				break
+			}
+		}
+		m.destInput.CursorEnd()
+		m.destInput.Focus()
+		return m, textinput.Blink
 	case key.Matches(msg, m.keys.Escape):
+		if m.queueSearchInput.Value() != "" {
+			m.queueSearchInput.SetValue("")
+			return m, nil
+		}
 		m.state = StateFileBrowser
 		m.selectedIndex = 0
 	case key.Matches(msg, m.keys.Start), msg.String() == "s":
-		if m.queue.Len() > 0 {
+		m.batch = m.batchQueue()
+		if m.batch.Len() > 0 {
+			// Show what will be downloaded before starting
+			m.state = StateQueuePreview
+			m.previewFiles = nil
+			m.previewLoading = true
+			return m, tea.Batch(m.loadPreview(), m.spinner.Tick)
+		}
+	}
+
+	return m, nil
+}
+
+// updateResumePrompt handles the offer to resume interrupted transfers
+func (m Model) updateResumePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg.String() {
+	case "y", "Y":
+		m.resumeInterrupted()
+		cmd = m.showFlash(fmt.Sprintf("Added %d interrupted transfers to the queue", len(m.interrupted)), 2*time.Second)
+	case "n", "N", "enter", "esc":
+	default:
+		return m, nil
+	}
+
+	// The progress file is rewritten when the resumed transfers start
+	_ = saveProgress(nil)
+	m.interrupted = nil
+	m.state = m.resumeReturn
+	return m, cmd
+}
+
+// updatePasteFile handles the file name prompt for pasting clipboard text to the remote
+func (m Model) updatePasteFile(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.pasteInput.Blur()
+		m.state = StateFileBrowser
+		return m, nil
+	case "enter":
+		name := strings.TrimSpace(m.pasteInput.Value())
+		if name == "" {
+			return m, nil
+		}
+		m.pasteInput.Blur()
+		m.state = StateFileBrowser
+		return m, m.pasteToFile(name)
+	}
+
+	var cmd tea.Cmd
+	m.pasteInput, cmd = m.pasteInput.Update(msg)
+	return m, cmd
+}
+
+// updateImportFilelist handles the prompt for a local file list to queue
+func (m Model) updateImportFilelist(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, m.keys.Paste) {
+		pasteInto(&m.importInput, m.clipboard)
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.importInput.Blur()
+		m.state = StateQueueView
+		return m, nil
+	case "enter":
+		listPath := strings.TrimSpace(m.importInput.Value())
+		if listPath == "" {
+			return m, nil
+		}
+		m.importInput.Blur()
+		m.state = StateQueueView
+		return m, m.importFilelist(listPath)
+	}
+
+	var cmd tea.Cmd
+	m.importInput, cmd = m.importInput.Update(msg)
+	return m, cmd
+}
+
+// updateDestinationInput handles the prompt for a new queue destination
+func (m Model) updateDestinationInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, m.keys.Paste) {
+		pasteInto(&m.destInput, m.clipboard)
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.destInput.Blur()
+		m.state = StateQueueView
+		return m, nil
+	case "enter":
+		dest := m.destInput.Value()
+		if err := m.queue.ChangeDestination(dest); err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.destInput.Blur()
+		m.state = StateQueueView
+		return m, m.showFlash("Pending downloads will go to "+dest, 2*time.Second)
+	}
+
+	var cmd tea.Cmd
+	m.destInput, cmd = m.destInput.Update(msg)
+	return m, cmd
+}
+
+// updateQueuePreview handles input in the download preview
+func (m Model) updateQueuePreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Enter):
+		if !m.previewLoading {
+			if m.batch.HasMirror() {
+				m.state = StateMirrorConfirm
+				return m, nil
+			}
 			m.state = StateTransferView
 			return m, m.startDownloads()
 		}
+	case key.Matches(msg, m.keys.Escape):
+		m.state = StateQueueView
+		m.previewLoading = false
 	}
+	return m, nil
+}
 
+// updateMirrorConfirm handles the confirmation required before mirroring
+func (m Model) updateMirrorConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		m.state = StateTransferView
+		return m, m.startDownloads()
+	case "n", "N", "esc":
+		m.state = StateQueuePreview
+	}
 	return m, nil
 }
 
 // updateTransferView handles input in transfer view
 func (m Model) updateTransferView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.spacePending {
+		switch msg.String() {
+		case "y", "Y":
+			return m, m.startDownloads()
+		case "n", "N", "esc":
+			m.spacePending = false
+			m.spaceWarning = ""
+			m.batch = nil
+			m.autoStarted = false
+			m.state = StateQueueView
+		}
+		return m, nil
+	}
 	if m.transferMgr == nil {
 		return m, nil
 	}
+	if m.rcFlag != "" {
+		return m.updateRCFlag(msg)
+	}
 
 	// Check if all done
-	pending, inProgress, _, _ := m.transferMgr.Stats()
+	pending, inProgress, completed, failed := m.transferMgr.Stats()
 	allDone := pending == 0 && inProgress == 0
 
+	if !allDone {
+		switch {
+		case key.Matches(msg, m.keys.BwLimit):
+			return m, m.openRCFlag("bwlimit")
+		case key.Matches(msg, m.keys.Transfers):
+			return m, m.openRCFlag("transfers")
+		case key.Matches(msg, m.keys.Checkers):
+			return m, m.openRCFlag("checkers")
+		}
+	}
+
+	// Column keys of the three-column layout; the active column does not scroll
+	switch {
+	case key.Matches(msg, m.keys.Left):
+		if m.transferColumn > 0 {
+			m.transferColumn--
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.Right):
+		if m.transferColumn < 2 {
+			m.transferColumn++
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.Up):
+		if m.transferColumn == 0 && m.colScrollPending > 0 {
+			m.colScrollPending--
+		} else if m.transferColumn == 2 && m.colScrollDone > 0 {
+			m.colScrollDone--
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.Down):
+		if m.transferColumn == 0 && m.colScrollPending < pending-1 {
+			m.colScrollPending++
+		} else if m.transferColumn == 2 && m.colScrollDone < completed+failed-1 {
+			m.colScrollDone++
+		}
+		return m, nil
+	}
+
 	if allDone {
 		switch {
 		case key.Matches(msg, m.keys.Enter):
-			m.queue.Clear()
-			m.transferMgr = nil
+			m.finishBatch()
 			m.state = StateFileBrowser
 			return m, nil
 		case msg.String() == "q":
@@ -277,6 +1486,18 @@ func (m Model) updateTransferView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // startDownloads initializes the transfer manager and starts downloads
 func (m *Model) startDownloads() tea.Cmd {
+	cwd := downloadDir()
+
+	// A batch that may not fit waits once for confirmation
+	if !m.spacePending {
+		m.spaceWarning = m.diskSpaceWarning(cwd)
+		if m.spaceWarning != "" {
+			m.spacePending = true
+			return nil
+		}
+	}
+	m.spacePending = false
+
 	// Create context for cancellation
 	ctx, cancel := context.WithCancel(context.Background())
 	m.transferCtx = ctx
@@ -284,34 +1505,97 @@ func (m *Model) startDownloads() tea.Cmd {
 
 	// Create transfer manager
 	m.transferMgr = rclone.NewTransferManager()
-
-	// Get current working directory
-	cwd, err := os.Getwd()
-	if err != nil {
-		cwd = "."
+	if m.config.Verbose {
+		m.transferMgr.WithHook(logHook{})
 	}
+	m.transferOpts = m.config.copyOptions()
 
-	// Add all queue items to transfer manager
-	items := m.queue.Items()
+	// Add all batch items to transfer manager
+	items := m.batch.Items()
+	m.batchTransfers = make([]batchTransfer, len(items))
 	for i, item := range items {
 		transferID := fmt.Sprintf("transfer_%d", i)
+		m.batchTransfers[i] = batchTransfer{id: transferID, item: item}
 		source := item.Remote + ":" + item.Path
-		m.transferMgr.Add(transferID, source, cwd, item.Size)
+		m.transferMgr.Add(transferID, source, itemDest(item, cwd), item.Size)
+		if item.Mirror {
+			m.transferMgr.SetMirror(transferID)
+		}
+		if item.BytesCopied > 0 && item.Size > 0 {
+			// Start resumed transfers where the previous run left off
+			m.transferMgr.UpdateProgress(transferID, float64(item.BytesCopied)/float64(item.Size)*100, item.BytesCopied, item.Size, "")
+		}
 	}
 
 	// Start all transfers in background goroutines
 	// Each transfer runs sequentially but doesn't block the UI
-	go m.runTransfers(ctx, cwd)
+	go m.runTransfers(ctx, cwd, m.batchTransfers)
 
-	// Start ticking to update the UI
-	return tickCmd()
+	// Start ticking to update the UI, and save progress in case we are killed
+	cmds := []tea.Cmd{tickCmd(), saveProgressTick()}
+
+	m.globalStats = nil
+	m.peakSpeed = 0
+	m.colScrollPending = 0
+	m.colScrollDone = 0
+	m.transferColumn = 0
+	if m.config.UseRC {
+		if ch, err := rclone.PollStats(ctx, m.config.RCAddr, time.Second); err == nil {
+			cmds = append(cmds, waitForStats(ch))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// diskSpaceWarning returns a warning when the batch may not fit in the free
+// space at dir, allowing 5% for overhead; unknown free space is no warning
+func (m Model) diskSpaceWarning(dir string) string {
+	free, err := rclone.FreeSpace(dir)
+	if err != nil {
+		return ""
+	}
+	needed := m.batch.TotalSize()
+	if float64(free) >= float64(needed)*1.05 {
+		return ""
+	}
+	return fmt.Sprintf("⚠ Disk space may be tight: %s free, %s to download", rclone.FormatSize(free), rclone.FormatSize(needed))
+}
+
+// downloadDir returns the local directory downloads are written to
+func downloadDir() string {
+	// Get current working directory
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "."
+	}
+	return cwd
+}
+
+// itemDest returns the local directory a queue item is downloaded into
+func itemDest(item queue.Item, defaultDir string) string {
+	if item.LocalPath != "" {
+		return item.LocalPath
+	}
+	return defaultDir
+}
+
+// batchTransfer is a batch item and the ID of the transfer that copies it
+type batchTransfer struct {
+	id   string
+	item queue.Item
 }
 
 // runTransfers runs all transfers sequentially in a background goroutine
-func (m *Model) runTransfers(ctx context.Context, cwd string) {
-	items := m.queue.Items()
+func (m *Model) runTransfers(ctx context.Context, cwd string, transfers []batchTransfer) {
+	for i, bt := range transfers {
+		// Give a throttled remote a rest between items
+		if i > 0 && (m.transferOpts.LowConcurrency || m.transferMgr.LowConcurrency()) {
+			select {
+			case <-ctx.Done():
+			case <-time.After(rclone.LowConcurrencyPause):
+			}
+		}
 
-	for i, item := range items {
 		// Check if cancelled
 		select {
 		case <-ctx.Done():
@@ -319,7 +1603,28 @@ func (m *Model) runTransfers(ctx context.Context, cwd string) {
 		default:
 		}
 
-		transferID := fmt.Sprintf("transfer_%d", i)
-		_ = rclone.CopyFile(ctx, m.transferMgr, transferID, item.Remote, item.Path, cwd)
+		item, transferID := bt.item, bt.id
+		opts := m.transferOpts
+		dest := itemDest(item, cwd)
+		if item.Policy == queue.PolicySkip {
+			opts.IgnoreExisting = true
+		}
+		if item.Mirror {
+			_ = rclone.MirrorDir(ctx, item.Remote, item.Path, dest, m.config.Excludes, m.transferMgr, transferID, opts)
+		} else if item.Policy == queue.PolicyRenameNew && item.IsDir {
+			_ = rclone.CopyDirRenameNew(ctx, m.transferMgr, transferID, item.Remote, item.Path, dest, opts)
+		} else if item.Policy == queue.PolicyRenameNew {
+			_ = rclone.CopyFileRenameNew(ctx, m.transferMgr, transferID, item.Remote, item.Path, dest, opts)
+		} else if item.IsDir {
+			_ = rclone.CopyDir(ctx, m.transferMgr, transferID, item.Remote, item.Path, dest, opts)
+		} else if item.Size > rclone.ChunkedCutoff {
+			_ = rclone.CopyChunkedWithOptions(ctx, m.transferMgr, transferID, item.Remote, item.Path, dest, 0, opts)
+		} else if opts.PreserveMetadata {
+			_ = rclone.CopyFileWithMetadata(ctx, m.transferMgr, transferID, item.Remote, item.Path, dest, item.ModTime, opts)
+		} else if m.remoteTypes[item.Remote] == "sftp" && opts.NativeSSHCompatible() {
+			_ = rclone.CopyFileSFTP(ctx, m.transferMgr, transferID, item.Remote, item.Path, dest, opts)
+		} else {
+			_ = rclone.CopyFileWithOptions(ctx, m.transferMgr, transferID, item.Remote, item.Path, dest, opts)
+		}
 	}
 }
@@ -1,19 +1,42 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
+	"rcloneb/queue"
 	"rcloneb/rclone"
+
+	"github.com/charmbracelet/lipgloss"
 )
 
 // View renders the current view
 func (m Model) View() string {
 	if m.err != nil {
-		return errorStyle.Render(fmt.Sprintf("Error: %v\n\nPress any key to continue...", m.err))
+		if m.waitOffered {
+			return m.withThumbnail(errorStyle.Render(fmt.Sprintf("Error: %v\n\n%s can't be reached. ctrl+r: wait for remote • any other key: continue", m.err, m.currentRemote)))
+		}
+		return m.withThumbnail(errorStyle.Render(fmt.Sprintf("Error: %v\n\nPress any key to continue...", m.err)))
+	}
+
+	view := m.stateView()
+	if m.showHelp {
+		view = m.helpOverlay(view)
+	}
+	if m.flash != "" {
+		view += "\n" + flashStyle.Render(m.flash)
 	}
+	return m.withThumbnail(view)
+}
 
+// stateView renders the view for the current state
+func (m Model) stateView() string {
 	switch m.state {
 	case StateRemoteSelect:
 		return m.remoteSelectView()
@@ -23,6 +46,34 @@ func (m Model) View() string {
 		return m.queueView()
 	case StateTransferView:
 		return m.transferView()
+	case StateFileViewer:
+		return m.fileViewerView()
+	case StateQueuePreview:
+		return m.queuePreviewView()
+	case StateCommandPalette:
+		return m.commandPaletteView()
+	case StateSettings:
+		return m.settingsView()
+	case StateMirrorConfirm:
+		return m.mirrorConfirmView()
+	case StateNewRemote:
+		return m.newRemoteView()
+	case StateAuthorizing:
+		return m.authorizingView()
+	case StateCloneRemote:
+		return m.remoteSelectView()
+	case StateConfigWizard:
+		return m.configWizardView()
+	case StateDestinationInput, StateImportFilelist:
+		return m.queueView()
+	case StateResumePrompt:
+		return m.resumePromptView()
+	case StatePasteFile, StatePurgeConfirm:
+		return m.fileBrowserView()
+	case StateDiagnostics:
+		return m.diagnosticsView()
+	case StateQuotaSummary:
+		return m.quotaSummaryView()
 	default:
 		return "Unknown state"
 	}
@@ -46,33 +97,104 @@ func (m Model) remoteSelectView() string {
 		return b.String()
 	}
 
+	section := ""
 	for i, remote := range m.remotes {
 		isSelected := i == m.selectedIndex
 
+		// Headers are drawn between remotes, so the cursor never lands on them
+		if typ, ok := m.remoteTypes[remote]; ok && (i == 0 || providerLabel(typ) != section) {
+			section = providerLabel(typ)
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString(headerStyle.Render("── " + section + " ──"))
+			b.WriteString("\n")
+		}
+
 		// Build line content with padding for bar effect
 		lineContent := " " + remote
+		if m.cryptRemotes[remote] {
+			if m.config.NoIcons {
+				lineContent = " [ENC] " + remote
+			} else {
+				lineContent = " 🔒 " + remote
+			}
+		}
+		if m.disabledRemotes[remote] {
+			lineContent += " [disabled]"
+		}
+		if m.remoteMarking {
+			checkbox := "[ ]"
+			if m.markedRemotes[remote] {
+				checkbox = "[x]"
+			}
+			lineContent = " " + checkbox + lineContent
+		}
 		lineWidth := m.width - 2
 		if lineWidth < 40 {
 			lineWidth = 40
 		}
-		if len(lineContent) < lineWidth {
-			lineContent += strings.Repeat(" ", lineWidth-len(lineContent))
+
+		// Quota bar, drawn red when the remote is nearly full
+		bar, barStyle := "", normalStyle
+		if q, ok := m.quotas[remote]; ok {
+			if len(lineContent) < quotaColumn {
+				lineContent += strings.Repeat(" ", quotaColumn-len(lineContent))
+			}
+			lineContent += " "
+			bar = quotaBar(q.UsedPercent)
+			if q.UsedPercent > 90 {
+				barStyle = errorStyle
+			}
+		}
+		padding := ""
+		if n := len(lineContent) + lipgloss.Width(bar); n < lineWidth {
+			padding = strings.Repeat(" ", lineWidth-n)
 		}
 
 		if isSelected {
-			b.WriteString(selectedStyle.Render(lineContent))
+			b.WriteString(selectedStyle.Render(lineContent + bar + padding))
 		} else {
-			b.WriteString(normalStyle.Render(lineContent))
+			b.WriteString(normalStyle.Render(lineContent) + barStyle.Render(bar) + normalStyle.Render(padding))
 		}
 		b.WriteString("\n")
 	}
 
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("j/k: navigate • enter: select • q: quit"))
+	if m.state == StateCloneRemote {
+		b.WriteString(filterPromptStyle.Render(m.cloneInput.View()))
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render(fmt.Sprintf("enter: copy %s's settings to this name • esc: cancel", m.remotes[m.selectedIndex])))
+		return b.String()
+	}
+	if m.remoteMarking {
+		b.WriteString(helpStyle.Render(fmt.Sprintf("%d marked • space: mark • ctrl+d: diagnose • ctrl+a: quotas • v/esc: done", len(m.markedRemotes))))
+		return b.String()
+	}
+	b.WriteString(helpStyle.Render("j/k: navigate • enter: select • v: mark • n: new remote • ctrl+d: clone • u: disk usage • d: diagnose • E: enable • ,: settings • q: quit" + m.staleMountsHint()))
+	if m.rcloneUpdate != "" {
+		b.WriteString("\n")
+		b.WriteString(cursorStyle.Render("[rclone " + m.rcloneUpdate + " available]"))
+	}
 
+	if m.unmountConfirm {
+		return m.overlay(b.String(), m.unmountConfirmView())
+	}
 	return b.String()
 }
 
+// quotaColumn is where quota bars start in the remote list
+const quotaColumn = 24
+
+// quotaBar draws a remote's used storage as a small bar, such as
+// "[████░░░░] 52%"
+func quotaBar(usedPercent float64) string {
+	const width = 8
+	filled := int(usedPercent/100*width + 0.5)
+	filled = min(max(filled, 0), width)
+	return fmt.Sprintf("[%s%s] %.0f%%", strings.Repeat("█", filled), strings.Repeat("░", width-filled), usedPercent)
+}
+
 // fileBrowserView renders the file browser view
 func (m Model) fileBrowserView() string {
 	var b strings.Builder
@@ -85,21 +207,87 @@ func (m Model) fileBrowserView() string {
 	b.WriteString(titleStyle.Render(path))
 	b.WriteString("\n")
 
-	// Queue indicator
+	if m.banner != "" {
+		b.WriteString(bannerStyle.Render(m.banner))
+		b.WriteString("\n")
+	}
+
+	for _, protocol := range m.serverProtocols() {
+		srv := m.servers[protocol]
+		line := fmt.Sprintf("[%s serving at %s]", srv.label, srv.url)
+		if srv.detail != "" {
+			line = fmt.Sprintf("[%s serving at %s • %s]", srv.label, srv.url, srv.detail)
+		}
+		b.WriteString(checkedStyle.Render(line))
+		b.WriteString("\n")
+	}
+
+	// Queue and mode indicators
+	var indicators []string
 	if m.queue.Len() > 0 {
-		b.WriteString(checkedStyle.Render(fmt.Sprintf("[%d files in queue]", m.queue.Len())))
+		indicators = append(indicators, checkedStyle.Render(fmt.Sprintf("[%d files in queue]", m.queue.Len())))
+	}
+	if m.rangeMode {
+		indicators = append(indicators, cursorStyle.Render("[RANGE]"))
+	}
+	if m.extract != nil {
+		indicators = append(indicators, cursorStyle.Render(m.extractIndicator()))
+	}
+	if m.config.SortOrder != "" {
+		indicators = append(indicators, cursorStyle.Render("[sort: "+m.config.SortOrder+"]"))
+	}
+	if m.config.DecryptNames && len(m.decrypted) > 0 {
+		indicators = append(indicators, cursorStyle.Render("[decrypted]"))
+	}
+	if m.hideSmall {
+		indicators = append(indicators, cursorStyle.Render("[≥ "+rclone.FormatSize(m.config.MinSizeFilter)+"]"))
+	}
+	if !m.cachedAt.IsZero() {
+		age := time.Since(m.cachedAt).Truncate(time.Second)
+		indicators = append(indicators, cursorStyle.Render("[cached "+age.String()+" ago]"))
+	}
+	if m.flatList {
+		indicators = append(indicators, cursorStyle.Render("[FLAT]"))
+	}
+	indicators = append(indicators, cursorStyle.Render("["+m.listMode.String()+"]"))
+	if m.dateFiltered() {
+		indicators = append(indicators, cursorStyle.Render(m.dateFilterLabel()))
+	}
+	if m.showRecent {
+		indicators = append(indicators, cursorStyle.Render(fmt.Sprintf("[RECENT %dh]", int(recentWindow.Hours()))))
+	}
+	if len(indicators) > 0 {
+		b.WriteString(strings.Join(indicators, " "))
 		b.WriteString("\n")
 	}
 	b.WriteString("\n")
 
 	if m.loading {
 		b.WriteString(m.spinner.View())
-		b.WriteString(" Loading...")
+		if m.waitCancel != nil {
+			b.WriteString(fmt.Sprintf(" Waiting for %s to come back, retrying every %s... (esc: stop)", m.currentRemote, waitForRemoteInterval))
+		} else {
+			b.WriteString(" Loading...")
+		}
 		return b.String()
 	}
 
-	// Filter input
-	if m.filterMode {
+	// Purge confirmation, paste-as-file prompt, then filter input
+	if m.state == StatePurgeConfirm {
+		b.WriteString(m.purgePromptView())
+		b.WriteString("\n\n")
+	} else if m.state == StatePasteFile {
+		b.WriteString(filterPromptStyle.Render(m.pasteInput.View()))
+		if m.config.CompressUploads {
+			b.WriteString(" " + cursorStyle.Render("[gzip]"))
+		}
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("enter: write clipboard text to this file • esc: cancel"))
+		b.WriteString("\n\n")
+	} else if m.dateMode {
+		b.WriteString(m.dateFilterPromptView())
+		b.WriteString("\n\n")
+	} else if m.filterMode {
 		b.WriteString(filterPromptStyle.Render("/ "))
 		b.WriteString(filterTextStyle.Render(m.filterInput.View()))
 		b.WriteString("\n\n")
@@ -132,6 +320,12 @@ func (m Model) fileBrowserView() string {
 			endIdx = len(files)
 		}
 
+		// The path minimap runs down the right of the listing
+		var minimap []string
+		if m.minimapEnabled() {
+			minimap = m.minimapLines(endIdx - startIdx)
+		}
+
 		for i := startIdx; i < endIdx; i++ {
 			f := files[i]
 			isSelected := i == m.fileIndex
@@ -143,7 +337,7 @@ func (m Model) fileBrowserView() string {
 			}
 
 			// File/dir name
-			name := f.Name
+			name := m.displayName(f.Name)
 			if f.IsDir {
 				name = name + "/"
 			}
@@ -154,26 +348,50 @@ func (m Model) fileBrowserView() string {
 				size = "  " + rclone.FormatSize(f.Size)
 			}
 
+			// Changed since the previous listing
+			change := ""
+			if m.changesKey == m.listingKey() {
+				change = m.changes[f.Path]
+			}
+			if change != "" {
+				size += "  (" + change + ")"
+			}
+
 			// Build the full line content
-			lineContent := fmt.Sprintf(" %s%s%s", checkbox, name, size)
+			icon := mimeIcon(f.TypeOrGuess(), m.config.NoIcons)
+			lineContent := fmt.Sprintf(" %s%s %s%s", checkbox, icon, name, size)
 
 			// Pad line to consistent width for full bar effect
 			lineWidth := m.width - 2
+			if m.thumbnailsEnabled() {
+				// Leave the right margin free for the thumbnail
+				lineWidth -= thumbnailCols + 2
+			}
+			if m.minimapEnabled() {
+				lineWidth -= minimapWidth + 2
+			}
 			if lineWidth < 40 {
 				lineWidth = 40
 			}
-			if len(lineContent) < lineWidth {
-				lineContent += strings.Repeat(" ", lineWidth-len(lineContent))
+			if w := lipgloss.Width(lineContent); w < lineWidth {
+				lineContent += strings.Repeat(" ", lineWidth-w)
 			}
 
 			// Apply styling based on selection
 			if isSelected {
 				b.WriteString(selectedStyle.Render(lineContent))
+			} else if change == "added" {
+				b.WriteString(successStyle.Render(lineContent))
+			} else if change == "modified" {
+				b.WriteString(warningStyle.Render(lineContent))
 			} else if f.IsDir {
 				b.WriteString(dirStyle.Render(lineContent))
 			} else {
 				b.WriteString(fileStyle.Render(lineContent))
 			}
+			if row := i - startIdx; row < len(minimap) {
+				b.WriteString("  " + minimap[row])
+			}
 			b.WriteString("\n")
 		}
 
@@ -181,29 +399,207 @@ func (m Model) fileBrowserView() string {
 		if len(files) > visibleLines {
 			b.WriteString(fmt.Sprintf("\n%d/%d", m.fileIndex+1, len(files)))
 		}
+
+		if m.showDetail && m.fileIndex >= 0 && m.fileIndex < len(files) {
+			b.WriteString("\n")
+			b.WriteString(m.detailView(files[m.fileIndex]))
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("j/k: navigate • space: select • a: all • l/enter: open • h: back • q: queue • /: filter • r: refresh • R: recent • L: flat • D: dates • ctrl+r: changes • i: details • p: view • M: mirror"))
+
+	return b.String()
+}
+
+// mimeIcon returns the marker shown before a listed name for its MIME type,
+// a bracketed label when plain is set
+func mimeIcon(mimeType string, plain bool) string {
+	kind, sub, _ := strings.Cut(mimeType, "/")
+	icon, label := "📄", "[   ]"
+	switch {
+	case mimeType == "inode/directory":
+		icon, label = "📁", "[DIR]"
+	case kind == "image":
+		icon, label = "📷", "[IMG]"
+	case kind == "video":
+		icon, label = "🎬", "[VID]"
+	case kind == "audio":
+		icon, label = "🎵", "[AUD]"
+	case sub == "pdf":
+		icon, label = "📕", "[PDF]"
+	case kind == "text" || sub == "json" || sub == "xml":
+		icon, label = "📝", "[TXT]"
+	case slices.Contains([]string{"zip", "gzip", "x-tar", "x-gzip", "x-bzip2", "x-xz", "x-7z-compressed", "x-rar-compressed", "vnd.rar", "zstd"}, sub):
+		icon, label = "📦", "[ARC]"
+	}
+	if plain {
+		return label
+	}
+	return icon
+}
+
+// detailView renders the detail panel for the file under the cursor
+func (m Model) detailView(f BrowserItem) string {
+	var b strings.Builder
+
+	b.WriteString(headerStyle.Render("Details"))
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf("Path:     %s:%s\n", m.currentRemote, f.Path))
+	if f.IsDir {
+		b.WriteString("Type:     directory\n")
+		return b.String()
+	}
+	b.WriteString(fmt.Sprintf("Size:     %s\n", rclone.FormatSize(f.Size)))
+	b.WriteString(fmt.Sprintf("Type:     %s\n", f.TypeOrGuess()))
+	if f.ModTime != "" {
+		b.WriteString(fmt.Sprintf("Modified: %s\n", f.ModTime))
+	}
+
+	for _, algo := range hashAlgorithms {
+		if hash, ok := m.hashCache[hashKey(m.currentRemote, f.Path, algo)]; ok {
+			b.WriteString(fmt.Sprintf("%-9s %s\n", algo+":", hash))
+		}
+	}
+	if m.hashing {
+		b.WriteString(m.spinner.View())
+		b.WriteString(" Computing checksum...\n")
+	} else {
+		b.WriteString(helpStyle.Render("c: compute checksum"))
+		b.WriteString("\n")
 	}
 
+	return b.String()
+}
+
+// viewerVisibleLines returns how many lines of file content fit on screen
+func (m Model) viewerVisibleLines() int {
+	visibleLines := m.height - 6
+	if visibleLines < 5 {
+		visibleLines = 10
+	}
+	return visibleLines
+}
+
+// fileViewerView renders the paginated file viewer
+func (m Model) fileViewerView() string {
+	var b strings.Builder
+
+	title := m.currentRemote + ":" + m.viewerPath
+	if m.viewerLocal {
+		title = m.viewerPath
+	}
+	b.WriteString(titleStyle.Render(title))
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("j/k: navigate • space: select • a: all • l/enter: open • h: back • q: queue • /: filter • r: refresh"))
+
+	lines := m.viewerLines()
+	visibleLines := m.viewerVisibleLines()
+	endIdx := m.viewerScroll + visibleLines
+	if endIdx > len(lines) {
+		endIdx = len(lines)
+	}
+	for i := m.viewerScroll; i < endIdx; i++ {
+		b.WriteString(normalStyle.Render(lines[i]))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	status := fmt.Sprintf("%s loaded", rclone.FormatSize(int64(len(m.viewerData))))
+	if m.viewerLoading {
+		status = m.spinner.View() + " Loading... " + status
+	} else if m.viewerEOF {
+		status += " (end of file)"
+	}
+	b.WriteString(status)
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("j/k: scroll • esc/h: back"))
 
 	return b.String()
 }
 
+// commandPaletteView renders the command palette overlay
+func (m Model) commandPaletteView() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Command Palette"))
+	b.WriteString("\n")
+	b.WriteString(m.paletteInput.View())
+	b.WriteString("\n\n")
+
+	matches := m.paletteMatches()
+	if len(matches) == 0 {
+		b.WriteString("No matching commands\n")
+	}
+	for i, c := range matches {
+		line := fmt.Sprintf(" %-22s %s", c.Name, c.Description)
+		if i == m.paletteIndex {
+			b.WriteString(selectedStyle.Render(line))
+		} else {
+			b.WriteString(normalStyle.Render(line))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("↑/↓: navigate • enter: run • esc: close"))
+
+	box := paletteStyle.Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
+
 // queueView renders the queue view
 func (m Model) queueView() string {
 	var b strings.Builder
 
 	b.WriteString(titleStyle.Render("Download Queue"))
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+	if m.config.BatchSize > 0 {
+		b.WriteString(checkedStyle.Render(fmt.Sprintf("[auto-start at %d]", m.config.BatchSize)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if m.state == StateImportFilelist {
+		b.WriteString(filterPromptStyle.Render(m.importInput.View()))
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render(fmt.Sprintf("one path per line, relative to %s:%s • enter: queue • esc: cancel", m.currentRemote, m.currentPath)))
+		b.WriteString("\n\n")
+	}
 
 	items := m.queue.Items()
 	if len(items) == 0 {
 		b.WriteString("Queue is empty\n")
 		b.WriteString("\n")
-		b.WriteString(helpStyle.Render("esc: go back"))
+		b.WriteString(helpStyle.Render("I: queue files from a list • esc: go back"))
 		return b.String()
 	}
 
+	// Search input and matches
+	matches := m.queueMatches()
+	if m.state == StateDestinationInput {
+		b.WriteString(filterPromptStyle.Render(m.destInput.View()))
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("enter: apply to pending items • esc: cancel"))
+		b.WriteString("\n\n")
+	} else if m.policyMenu {
+		b.WriteString(m.bulkPolicyView())
+		b.WriteString("\n\n")
+	} else if m.tagMode {
+		b.WriteString(filterPromptStyle.Render(m.tagInput.View()))
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("comma-separated • enter: save • esc: cancel"))
+		b.WriteString("\n\n")
+	} else if m.queueSearchMode {
+		b.WriteString(filterPromptStyle.Render(m.queueSearchInput.View()))
+		b.WriteString("\n\n")
+	} else if _, ok := m.queueSearchTag(); ok {
+		b.WriteString(filterPromptStyle.Render(fmt.Sprintf("Find: %s (%d matches, d: remove all • p: pause/resume • s: start these)", m.queueSearchInput.Value(), len(matches))))
+		b.WriteString("\n\n")
+	} else if m.queueSearchInput.Value() != "" {
+		b.WriteString(filterPromptStyle.Render(fmt.Sprintf("Find: %s (%d matches, d: remove all)", m.queueSearchInput.Value(), len(matches))))
+		b.WriteString("\n\n")
+	}
+
 	// Calculate visible range
 	visibleLines := m.height - 8
 	if visibleLines < 5 {
@@ -237,6 +633,22 @@ func (m Model) queueView() string {
 
 		// Build line content
 		lineContent := fmt.Sprintf(" %s  %s  (%s)", name, sizeStr, item.Remote)
+		if item.Mirror {
+			lineContent += "  [MIRROR]"
+		}
+		if item.LocalPath != "" {
+			lineContent += "  → " + item.LocalPath
+		}
+		for _, tag := range item.Tags {
+			lineContent += "  #" + tag
+		}
+		if item.Held {
+			lineContent += "  [PAUSED]"
+		}
+		if !item.Mirror {
+			// Mirrors always replace local files
+			lineContent += "  " + item.Policy.Badge()
+		}
 
 		// Pad line for bar effect
 		lineWidth := m.width - 2
@@ -249,6 +661,8 @@ func (m Model) queueView() string {
 
 		if isSelected {
 			b.WriteString(selectedStyle.Render(lineContent))
+		} else if matches[i] {
+			b.WriteString(cursorStyle.Render(lineContent))
 		} else {
 			b.WriteString(normalStyle.Render(lineContent))
 		}
@@ -257,8 +671,142 @@ func (m Model) queueView() string {
 
 	b.WriteString("\n")
 	b.WriteString(fmt.Sprintf("Total: %d files, %s\n", len(items), rclone.FormatSize(m.queue.TotalSize())))
+	if breakdown := m.queueSizeBreakdown(); breakdown != "" {
+		b.WriteString(helpStyle.Render(breakdown))
+		b.WriteString("\n")
+	}
+	if breakdown := m.queueExtBreakdown(); breakdown != "" {
+		b.WriteString(helpStyle.Render(breakdown))
+		b.WriteString("\n")
+	}
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("j/k: navigate • d/x: remove • s: start download • esc: go back"))
+	b.WriteString(helpStyle.Render("j/k: navigate • d/x: remove • /: find (tag:name for tags) • t: tags • p: pause • c: on conflict • P: bulk policy • C: clear completed • I: import list • ctrl+d: destination • s: start download • esc: go back"))
+
+	return b.String()
+}
+
+// queueSizeBreakdown summarises queued bytes per status, skipping empty statuses
+func (m Model) queueSizeBreakdown() string {
+	totals := m.queue.TotalSizeByStatus()
+	labels := []struct {
+		status queue.ItemStatus
+		label  string
+	}{
+		{queue.StatusPending, "Pending"},
+		{queue.StatusDownloading, "Active"},
+		{queue.StatusCompleted, "Done"},
+		{queue.StatusError, "Failed"},
+	}
+
+	var parts []string
+	for _, l := range labels {
+		if size, ok := totals[l.status]; ok && size > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %s", l.label, rclone.FormatSize(size)))
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+// queueExtBreakdownSize is the number of extensions listed in the queue footer
+const queueExtBreakdownSize = 5
+
+// queueExtBreakdown summarises the queue by file extension, largest first
+func (m Model) queueExtBreakdown() string {
+	summary := m.queue.SummarizeByExtension()
+	exts := make([]string, 0, len(summary))
+	for ext := range summary {
+		exts = append(exts, ext)
+	}
+	sort.Slice(exts, func(i, j int) bool {
+		a, b := summary[exts[i]], summary[exts[j]]
+		if a.TotalBytes != b.TotalBytes {
+			return a.TotalBytes > b.TotalBytes
+		}
+		return exts[i] < exts[j]
+	})
+
+	var parts []string
+	for i, ext := range exts {
+		if i == queueExtBreakdownSize {
+			parts = append(parts, fmt.Sprintf("+%d more", len(exts)-i))
+			break
+		}
+		s := summary[ext]
+		noun := "files"
+		if ext == "[dir]" {
+			noun = "dirs"
+		}
+		if s.Count == 1 {
+			noun = strings.TrimSuffix(noun, "s")
+		}
+		parts = append(parts, fmt.Sprintf("%s: %d %s (%s)", ext, s.Count, noun, rclone.FormatSize(s.TotalBytes)))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// queuePreviewView renders the dry-run preview shown before downloads start
+func (m Model) queuePreviewView() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Download Preview"))
+	b.WriteString("\n\n")
+
+	if m.previewLoading {
+		b.WriteString(m.spinner.View())
+		b.WriteString(" Checking what will be downloaded...")
+		return b.String()
+	}
+
+	b.WriteString(fmt.Sprintf("These %d files will be downloaded (%s):\n\n",
+		len(m.previewFiles), rclone.FormatSize(m.batch.TotalSize())))
+
+	visibleLines := m.height - 10
+	if visibleLines < 5 {
+		visibleLines = 10
+	}
+	for i, name := range m.previewFiles {
+		if i >= visibleLines {
+			b.WriteString(helpStyle.Render(fmt.Sprintf("  ... and %d more", len(m.previewFiles)-i)))
+			b.WriteString("\n")
+			break
+		}
+		b.WriteString(queueItemStyle.Render(name))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("enter: start download • esc: back to queue"))
+
+	return b.String()
+}
+
+// mirrorConfirmView renders the confirmation shown before mirroring directories
+func (m Model) mirrorConfirmView() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Confirm Mirror"))
+	b.WriteString("\n\n")
+	b.WriteString(warningStyle.Render("Mirroring deletes local files that are not on the remote."))
+	b.WriteString("\n\n")
+
+	cwd := downloadDir()
+	for _, item := range m.batch.Items() {
+		if !item.Mirror {
+			continue
+		}
+		dst := filepath.Join(itemDest(item, cwd), path.Base(item.Path))
+		b.WriteString(queueItemStyle.Render(fmt.Sprintf("%s:%s → %s", item.Remote, item.Path, dst)))
+		b.WriteString("\n")
+	}
+
+	if len(m.config.Excludes) > 0 {
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("Excluded and deleted locally: " + strings.Join(m.config.Excludes, ", ")))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("y: start mirroring • n/esc: back to preview"))
 
 	return b.String()
 }
@@ -270,6 +818,17 @@ func (m Model) transferView() string {
 	b.WriteString(titleStyle.Render("Downloading..."))
 	b.WriteString("\n\n")
 
+	if m.spaceWarning != "" {
+		b.WriteString(warningStyle.Render(m.spaceWarning))
+		b.WriteString("\n")
+		if m.spacePending {
+			b.WriteString(helpStyle.Render("y: download anyway • n/esc: back to queue"))
+			b.WriteString("\n")
+			return b.String()
+		}
+		b.WriteString("\n")
+	}
+
 	if m.transferMgr == nil {
 		b.WriteString("Initializing transfers...\n")
 		return b.String()
@@ -280,7 +839,17 @@ func (m Model) transferView() string {
 	statsLine := fmt.Sprintf("Pending: %d | Active: %d | Done: %d | Failed: %d",
 		pending, inProgress, completed, failed)
 	b.WriteString(statsLine)
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf("Total transferred this session: %s\n", rclone.FormatSize(m.sessionTransferred())))
+	if m.transferMgr.LowConcurrency() && !m.config.LowConcurrencyMode {
+		b.WriteString(warningStyle.Render(fmt.Sprintf("Throttled %d times in a row: later transfers use --transfers 1 --checkers 1 and wait %s between files", rclone.LowConcurrencyThreshold, rclone.LowConcurrencyPause)))
+		b.WriteString("\n")
+	}
+	if m.globalStats != nil {
+		b.WriteString(m.globalStatsView())
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
 
 	transfers := m.transferMgr.GetAll()
 	if len(transfers) == 0 {
@@ -288,31 +857,16 @@ func (m Model) transferView() string {
 		return b.String()
 	}
 
-	// Show in-progress transfers first
-	for _, t := range transfers {
-		if t.Status == rclone.StatusInProgress {
-			b.WriteString(m.renderTransfer(t))
-		}
-	}
-
-	// Then pending
-	for _, t := range transfers {
-		if t.Status == rclone.StatusPending {
-			b.WriteString(m.renderTransfer(t))
-		}
-	}
-
-	// Then completed
-	for _, t := range transfers {
-		if t.Status == rclone.StatusCompleted {
-			b.WriteString(m.renderTransfer(t))
-		}
-	}
-
-	// Then failed
-	for _, t := range transfers {
-		if t.Status == rclone.StatusFailed {
-			b.WriteString(m.renderTransfer(t))
+	if m.width >= transferColumnsMinWidth {
+		b.WriteString(m.transferColumnsView(transfers))
+	} else {
+		// Too narrow for columns: active, pending, completed then failed in one list
+		for _, status := range []rclone.TransferStatus{rclone.StatusInProgress, rclone.StatusRateLimited, rclone.StatusPending, rclone.StatusCompleted, rclone.StatusFailed} {
+			for _, t := range transfers {
+				if t.Status == status {
+					b.WriteString(m.renderTransfer(t, m.width))
+				}
+			}
 		}
 	}
 
@@ -329,15 +883,143 @@ func (m Model) transferView() string {
 		}
 		b.WriteString("\n\n")
 		b.WriteString(helpStyle.Render("enter: continue browsing • q: quit"))
+	} else if m.rcFlag != "" {
+		b.WriteString(filterPromptStyle.Render(m.rcFlagInput.View()))
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("enter: apply to the running transfer • esc: cancel"))
+	} else {
+		b.WriteString(helpStyle.Render("Downloads in progress... ctrl+c: cancel • b: bandwidth limit • T: transfers • C: checkers"))
+	}
+	if m.width >= transferColumnsMinWidth {
+		b.WriteString(helpStyle.Render("h/l: switch column • j/k: scroll column"))
+	}
+
+	return b.String()
+}
+
+// transferColumnsMinWidth is the narrowest terminal the three-column transfer view is used in
+const transferColumnsMinWidth = 90
+
+// pendingColumnSize is the number of transfers shown in the pending column
+const pendingColumnSize = 5
+
+// transferColumnsView renders pending, active and finished transfers side by side
+func (m Model) transferColumnsView(transfers []*rclone.Transfer) string {
+	var pending, active, done []*rclone.Transfer
+	for _, t := range transfers {
+		switch t.Status {
+		case rclone.StatusPending:
+			pending = append(pending, t)
+		case rclone.StatusInProgress, rclone.StatusRateLimited:
+			active = append(active, t)
+		default:
+			done = append(done, t)
+		}
+	}
+
+	colWidth := (m.width - 4) / 3
+	doneSize := (m.height - 14) / 2
+	if doneSize < pendingColumnSize {
+		doneSize = pendingColumnSize
+	}
+
+	columns := []string{
+		m.renderTransferColumn(0, "Pending", pending, m.colScrollPending, pendingColumnSize, colWidth),
+		m.renderTransferColumn(1, "Active", active, 0, len(active), colWidth),
+		m.renderTransferColumn(2, "Done", done, m.colScrollDone, doneSize, colWidth),
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, columns...) + "\n"
+}
+
+// renderTransferColumn renders up to size transfers starting at offset under a header
+func (m Model) renderTransferColumn(index int, title string, transfers []*rclone.Transfer, offset, size, width int) string {
+	var b strings.Builder
+
+	header := fmt.Sprintf("%s (%d)", title, len(transfers))
+	if index == m.transferColumn {
+		b.WriteString(selectedStyle.Render(header))
 	} else {
-		b.WriteString(helpStyle.Render("Downloads in progress... ctrl+c: cancel"))
+		b.WriteString(headerStyle.Render(header))
+	}
+	b.WriteString("\n")
+
+	if offset > len(transfers) {
+		offset = len(transfers)
+	}
+	end := offset + size
+	if end > len(transfers) {
+		end = len(transfers)
+	}
+	if offset > 0 {
+		b.WriteString(fmt.Sprintf("↑ %d more\n", offset))
+	}
+	for _, t := range transfers[offset:end] {
+		b.WriteString(m.renderTransfer(t, width))
+	}
+	if end < len(transfers) {
+		b.WriteString(fmt.Sprintf("↓ %d more\n", len(transfers)-end))
 	}
 
+	return lipgloss.NewStyle().Width(width).MarginRight(1).Render(b.String())
+}
+
+// globalStatsView renders the live RC speed gauge, scaled to the peak speed seen
+func (m Model) globalStatsView() string {
+	const gaugeWidth = 20
+	s := m.globalStats
+
+	filled := 0
+	if m.peakSpeed > 0 {
+		filled = int(float64(gaugeWidth) * float64(s.Speed) / float64(m.peakSpeed))
+	}
+	gauge := progressBarStyle.Render(strings.Repeat("█", filled)) + strings.Repeat("░", gaugeWidth-filled)
+
+	return fmt.Sprintf("Global: [%s] %s | %s transferred | %d errors | %d checks",
+		gauge, rclone.FormatSpeed(float64(s.Speed)), rclone.FormatSize(s.BytesTransferred), s.Errors, s.Checks)
+}
+
+// renderTransfer renders a single transfer with progress bar, fitting the bar into width
+// sparklineWidth is how many seconds of speed a transfer's sparkline shows
+const sparklineWidth = 20
+
+// sparkBlocks are the sparkline levels, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders samples as block characters scaled to the largest one,
+// right-aligned in width columns
+func sparkline(samples []float64, width int) string {
+	if len(samples) > width {
+		samples = samples[len(samples)-width:]
+	}
+	peak := slices.Max(samples)
+
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", width-len(samples)))
+	for _, s := range samples {
+		level := 0
+		if peak > 0 {
+			level = int(s / peak * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
 	return b.String()
 }
 
-// renderTransfer renders a single transfer with progress bar
-func (m Model) renderTransfer(t *rclone.Transfer) string {
+// indeterminateBar renders a progress bar of the given width with a block
+// sliding back and forth, for transfers of unknown size
+func indeterminateBar(width int) string {
+	const block = 6
+	span := width - block
+	pos := int(time.Now().UnixMilli()/100) % (2 * span)
+	if pos > span {
+		pos = 2*span - pos
+	}
+	return strings.Repeat("░", pos) +
+		progressBarStyle.Render(strings.Repeat("█", block)) +
+		strings.Repeat("░", span-pos)
+}
+
+func (m Model) renderTransfer(t *rclone.Transfer, width int) string {
 	var b strings.Builder
 
 	// Extract filename from source path
@@ -363,15 +1045,41 @@ func (m Model) renderTransfer(t *rclone.Transfer) string {
 	case rclone.StatusFailed:
 		statusPrefix = errorStyle.Render("[FAILED]  ")
 		style = errorStyle
+	case rclone.StatusRateLimited:
+		statusPrefix = warningStyle.Render("[WAITING] ")
+		style = warningStyle
 	}
 
 	// First line: status + filename
-	b.WriteString(fmt.Sprintf("%s%s\n", statusPrefix, style.Render(filename)))
+	b.WriteString(fmt.Sprintf("%s%s", statusPrefix, style.Render(filename)))
+	if t.Mirror {
+		b.WriteString(" " + warningStyle.Render("[MIRROR]"))
+	}
+	if m.transferOpts.IgnoreExisting {
+		// Skipped files sit at 0% and then complete without copying
+		b.WriteString(" " + cursorStyle.Render("[skip-existing]"))
+	}
+	if m.transferOpts.PreserveMetadata {
+		b.WriteString(" " + cursorStyle.Render("[meta]"))
+	}
+	if m.transferOpts.SkipNewerAtDest {
+		b.WriteString(" " + cursorStyle.Render("[update-mode]"))
+	}
+	if m.transferOpts.Immutable {
+		b.WriteString(" " + cursorStyle.Render("[immutable]"))
+	}
+	if m.transferOpts.NoCheckDest {
+		b.WriteString(" " + warningStyle.Render("[no-check]"))
+	}
+	if t.Status == rclone.StatusRateLimited {
+		b.WriteString(" " + warningStyle.Render("[rate limited]"))
+	}
+	b.WriteString("\n")
 
 	// Progress bar for in-progress transfers
 	if t.Status == rclone.StatusInProgress {
 		// Calculate progress bar width based on terminal width
-		barWidth := m.width - 25
+		barWidth := width - 25
 		if barWidth < 20 {
 			barWidth = 20
 		}
@@ -394,7 +1102,19 @@ func (m Model) renderTransfer(t *rclone.Transfer) string {
 		bar := progressBarStyle.Render(strings.Repeat("█", filled)) +
 			strings.Repeat("░", empty)
 
-		b.WriteString(fmt.Sprintf("   [%s] %.0f%%\n", bar, t.Progress))
+		if t.CheckingFile != "" && t.BytesCopied == 0 {
+			// rclone compares files before copying anything
+			b.WriteString(helpStyle.Render("   Checking: " + t.CheckingFile))
+		} else if t.BytesTotal <= 0 && t.Progress == 0 {
+			// The size is unknown until rclone's first stats
+			b.WriteString(fmt.Sprintf("   [%s]", indeterminateBar(barWidth)))
+		} else {
+			b.WriteString(fmt.Sprintf("   [%s] %.0f%%", bar, t.Progress))
+		}
+		if t.ChunksTotal > 0 {
+			b.WriteString(fmt.Sprintf("  chunks %d/%d", t.ChunksDone, t.ChunksTotal))
+		}
+		b.WriteString("\n")
 
 		// Stats line: bytes transferred, speed
 		if t.BytesTotal > 0 {
@@ -404,23 +1124,43 @@ func (m Model) renderTransfer(t *rclone.Transfer) string {
 			if t.Speed != "" {
 				stats += fmt.Sprintf(" @ %s", t.Speed)
 			}
+			if t.ProgressTitle != "" && t.Status == rclone.StatusInProgress {
+				stats += "  " + t.ProgressTitle
+			}
 			b.WriteString(helpStyle.Render(stats))
 			b.WriteString("\n")
 		} else if t.Speed != "" {
 			b.WriteString(helpStyle.Render(fmt.Sprintf("   %s", t.Speed)))
 			b.WriteString("\n")
 		}
+
+		// Speed over the last sparklineWidth seconds
+		if samples := m.transferMgr.GetSpeedGraph(t.ID, sparklineWidth); len(samples) > 1 {
+			b.WriteString("   " + progressBarStyle.Render(sparkline(samples, sparklineWidth)))
+			b.WriteString("\n")
+		}
+
+		if lines := t.LogLines(); t.RetryCount > 0 && len(lines) > 0 {
+			b.WriteString(bannerStyle.Render("   " + lines[len(lines)-1]))
+			b.WriteString("\n")
+		}
 	}
 
 	// Completed: show duration
 	if t.Status == rclone.StatusCompleted && !t.EndTime.IsZero() {
 		duration := t.EndTime.Sub(t.StartTime).Round(time.Millisecond)
 		b.WriteString(helpStyle.Render(fmt.Sprintf("   Completed in %v", duration)))
+		if t.ChecksumFile != "" {
+			b.WriteString(" " + successStyle.Render("[.md5 written]"))
+		}
 		b.WriteString("\n")
 	}
 
 	// Failed: show error
-	if t.Status == rclone.StatusFailed && t.Error != nil {
+	if t.Status == rclone.StatusFailed && errors.Is(t.Error, rclone.ErrImmutableConflict) {
+		b.WriteString(warningStyle.Render("   File already exists—immutable mode"))
+		b.WriteString("\n")
+	} else if t.Status == rclone.StatusFailed && t.Error != nil {
 		b.WriteString(errorStyle.Render(fmt.Sprintf("   Error: %v", t.Error)))
 		b.WriteString("\n")
 	}